@@ -0,0 +1,143 @@
+package authz
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+)
+
+var (
+	// ErrNotFound hides items the user may not even know about
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden means the user can see the item but lacks the permission
+	ErrForbidden = errors.New("permission denied")
+)
+
+// Permissions returns what the user's role grants. Unknown roles grant nothing.
+func Permissions(db *gorm.DB, user *models.User) []Permission {
+	var role models.Role
+	if err := db.Where("name = ?", user.Role).First(&role).Error; err != nil {
+		return []Permission{}
+	}
+	permissions := make([]Permission, len(role.Permissions))
+	for i, p := range role.Permissions {
+		permissions[i] = Permission(p)
+	}
+	return permissions
+}
+
+// Has reports whether the user's role grants a permission
+func Has(db *gorm.DB, user *models.User, p Permission) bool {
+	if user.Disabled {
+		return false
+	}
+	for _, granted := range Permissions(db, user) {
+		if granted == p || granted == All {
+			return true
+		}
+	}
+	return false
+}
+
+// UserHas is Has for a user ID
+func UserHas(db *gorm.DB, userID uint, p Permission) bool {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return false
+	}
+	return Has(db, &user, p)
+}
+
+// CheckItem decides whether the user may exercise p on an item owned by
+// ownerID, optionally inside a team drive. Personal items of other users are
+// reported as not found.
+func CheckItem(db *gorm.DB, userID, ownerID uint, driveID *uint, p Permission) error {
+	if driveID != nil {
+		return CheckDrive(db, *driveID, userID, p)
+	}
+	if ownerID != userID {
+		return ErrNotFound
+	}
+	if !UserHas(db, userID, p) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CheckSpace decides whether the user may exercise p on their personal space,
+// or on a team drive as a whole when driveID is set
+func CheckSpace(db *gorm.DB, userID uint, driveID *uint, p Permission) error {
+	return CheckItem(db, userID, userID, driveID, p)
+}
+
+// CheckDrive decides whether the user may exercise p inside a team drive.
+// Both their drive role and their own role must allow it.
+func CheckDrive(db *gorm.DB, driveID, userID uint, p Permission) error {
+	role := DriveRole(db, driveID, userID)
+	if role == "" {
+		return ErrNotFound
+	}
+	if !DriveRoleAllows(role, p) || !UserHas(db, userID, p) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// DriveRole returns the user's role in a team drive, or "" when they are not
+// a member of the owning group. Group admins are managers.
+func DriveRole(db *gorm.DB, driveID, userID uint) string {
+	var drive models.TeamDrive
+	if err := db.First(&drive, driveID).Error; err != nil {
+		return ""
+	}
+
+	isAdmin, isMember := GroupMembership(db, drive.GroupID, userID)
+	if isAdmin {
+		return models.DriveRoleManager
+	}
+	if !isMember {
+		return ""
+	}
+
+	var member models.TeamDriveMember
+	if err := db.Where("drive_id = ? AND user_id = ?", driveID, userID).First(&member).Error; err == nil {
+		return member.Role
+	}
+	return drive.DefaultRole
+}
+
+// GroupMembership reports whether the user administers and whether they are
+// a member of a group. Holders of admin.groups.manage administer every group.
+func GroupMembership(db *gorm.DB, groupID, userID uint) (isAdmin, isMember bool) {
+	if UserHas(db, userID, AdminGroupsManage) {
+		return true, true
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		return false, false
+	}
+	return member.IsAdmin, true
+}
+
+// ReadableDriveIDs selects the IDs of the team drives the user can access
+func ReadableDriveIDs(db *gorm.DB, userID uint) *gorm.DB {
+	if UserHas(db, userID, AdminGroupsManage) {
+		return db.Model(&models.TeamDrive{}).Select("id")
+	}
+	groupIDs := db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID)
+	return db.Model(&models.TeamDrive{}).Select("id").Where("group_id IN (?)", groupIDs)
+}
+
+// CanGrant reports whether the user holds every permission of a role, so
+// that assigning or editing it cannot escalate their own privileges
+func CanGrant(db *gorm.DB, user *models.User, role models.Role) bool {
+	for _, p := range role.Permissions {
+		if !Has(db, user, Permission(p)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+// Package authz decides what a user may do. Permissions are bundled into
+// roles assigned to users; inside team drives the member's drive role limits
+// them further, and personal items are only accessible to their owner.
+package authz
+
+import (
+	"a-drive-backend/models"
+)
+
+// Permission is a named capability granted through roles
+type Permission string
+
+const (
+	FilesRead    Permission = "files.read"
+	FilesWrite   Permission = "files.write"
+	SharesCreate Permission = "shares.create"
+	GroupsCreate Permission = "groups.create"
+	DrivesCreate Permission = "drives.create"
+	DrivesManage Permission = "drives.manage"
+
+	AnalyticsSystem   Permission = "analytics.system"
+	AdminUsersManage  Permission = "admin.users.manage"
+	AdminFilesBrowse  Permission = "admin.files.browse"
+	AdminConfigRead   Permission = "admin.config.read"
+	AdminRolesManage  Permission = "admin.roles.manage"
+	AdminGroupsManage Permission = "admin.groups.manage"
+	AdminMaintenance  Permission = "admin.maintenance"
+
+	// All grants every permission, including ones added later
+	All Permission = "*"
+)
+
+// Definition describes a permission for the admin UI
+type Definition struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// Definitions lists every permission that can be put into a role
+var Definitions = []Definition{
+	{FilesRead, "Browse, search and download files"},
+	{FilesWrite, "Upload, create, rename, move and delete files and folders, and manage versions"},
+	{SharesCreate, "Create and manage share links"},
+	{GroupsCreate, "Create groups"},
+	{DrivesCreate, "Create team drives for groups they administer"},
+	{DrivesManage, "Edit team drives and member roles where they are drive managers"},
+	{AnalyticsSystem, "View system-wide analytics"},
+	{AdminUsersManage, "List, create, disable, delete users and assign roles"},
+	{AdminFilesBrowse, "Browse the files of any user"},
+	{AdminConfigRead, "View the server configuration"},
+	{AdminRolesManage, "Define roles and their permissions"},
+	{AdminGroupsManage, "Administer every group and team drive"},
+	{AdminMaintenance, "Turn maintenance mode on and off"},
+}
+
+// Valid reports whether name is a known permission or the wildcard
+func Valid(name string) bool {
+	if Permission(name) == All {
+		return true
+	}
+	for _, d := range Definitions {
+		if string(d.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Names of the built-in roles
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// BuiltInRoles are created at startup when missing. The admin role always
+// keeps every permission; the user role is the default for new accounts.
+func BuiltInRoles() []models.Role {
+	return []models.Role{
+		{
+			Name:        RoleAdmin,
+			Description: "Full access to everything",
+			Permissions: models.StringList{string(All)},
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleUser,
+			Description: "Regular account with personal storage",
+			Permissions: models.StringList{
+				string(FilesRead), string(FilesWrite), string(SharesCreate),
+				string(GroupsCreate), string(DrivesCreate), string(DrivesManage),
+			},
+			BuiltIn: true,
+		},
+	}
+}
+
+// drivePermissions are the permissions each team drive role allows. A member
+// also needs the permission through their own role.
+var drivePermissions = map[string][]Permission{
+	models.DriveRoleReader:      {FilesRead},
+	models.DriveRoleContributor: {FilesRead, FilesWrite},
+	models.DriveRoleManager:     {FilesRead, FilesWrite, SharesCreate, DrivesManage},
+}
+
+// DriveRoleAllows reports whether a team drive role allows a permission
+func DriveRoleAllows(role string, p Permission) bool {
+	for _, allowed := range drivePermissions[role] {
+		if allowed == p {
+			return true
+		}
+	}
+	return false
+}
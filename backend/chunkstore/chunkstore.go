@@ -0,0 +1,200 @@
+// Package chunkstore splits content into content-defined chunks and stores
+// each distinct chunk once, named by its SHA-256 hash.
+package chunkstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Chunk sizes. Boundaries depend only on the bytes just before them, so an
+// edit only changes the chunks around it and the rest are shared.
+const (
+	MinSize = 16 << 10
+	AvgSize = 64 << 10
+	MaxSize = 256 << 10
+)
+
+// Boundary masks on the high bits of the gear hash, which depend on the last
+// 64 bytes. The stricter mask is used below AvgSize so that chunk sizes
+// cluster around it.
+const (
+	maskStrict = 0xFFFFC00000000000 // 18 bits
+	maskLoose  = 0xFFFC000000000000 // 14 bits
+)
+
+var gear [256]uint64
+
+func init() {
+	// A fixed seed keeps boundaries, and so deduplication, stable across runs
+	seed := uint64(0x6a09e667f3bcc908)
+	for i := range gear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// Split reads r to the end and calls fn with each chunk. The slice is only
+// valid until fn returns.
+func Split(r io.Reader, fn func(chunk []byte) error) error {
+	buf := make([]byte, MaxSize)
+	n := 0
+	eof := false
+	for {
+		if !eof {
+			read, err := io.ReadFull(r, buf[n:])
+			n += read
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if n == 0 {
+			return nil
+		}
+
+		size := boundary(buf[:n])
+		if err := fn(buf[:size]); err != nil {
+			return err
+		}
+		n = copy(buf, buf[size:n])
+	}
+}
+
+// boundary returns the length of the first chunk of data
+func boundary(data []byte) int {
+	if len(data) <= MinSize {
+		return len(data)
+	}
+	end := len(data)
+	if end > MaxSize {
+		end = MaxSize
+	}
+	normal := end
+	if normal > AvgSize {
+		normal = AvgSize
+	}
+
+	var h uint64
+	i := MinSize
+	for ; i < normal; i++ {
+		h = (h << 1) + gear[data[i]]
+		if h&maskStrict == 0 {
+			return i + 1
+		}
+	}
+	for ; i < end; i++ {
+		h = (h << 1) + gear[data[i]]
+		if h&maskLoose == 0 {
+			return i + 1
+		}
+	}
+	return end
+}
+
+// Hash names a chunk
+func Hash(chunk []byte) string {
+	sum := sha256.Sum256(chunk)
+	return hex.EncodeToString(sum[:])
+}
+
+// Store keeps chunks as files below a directory
+type Store struct {
+	Dir string
+}
+
+func New(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.Dir, hash[:2], hash)
+}
+
+// Put stores a chunk unless it is already present and returns its hash
+func (s *Store) Put(chunk []byte) (string, error) {
+	hash := Hash(chunk)
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".chunk-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(chunk)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash, os.Rename(tmp.Name(), path)
+}
+
+// Open reassembles the chunks in order. The reader opens one chunk at a time.
+func (s *Store) Open(hashes []string) io.ReadCloser {
+	return &reader{store: s, hashes: hashes}
+}
+
+// Remove deletes a chunk. Removing a missing chunk is not an error.
+func (s *Store) Remove(hash string) error {
+	if err := os.Remove(s.path(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+type reader struct {
+	store   *Store
+	hashes  []string
+	current *os.File
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.hashes) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(r.store.path(r.hashes[0]))
+			if err != nil {
+				return 0, err
+			}
+			r.current = f
+			r.hashes = r.hashes[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *reader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ListUsers returns every account (admin only)
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var resp struct {
+		Users []User `json:"users"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/users", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+// CreateUser creates a regular account (admin only)
+func (c *Client) CreateUser(ctx context.Context, username, email, password string) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	body := map[string]string{"username": username, "email": email, "password": password}
+	if err := c.postJSON(ctx, "/api/admin/users", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+// SetUserRole assigns a role to a user by name (admin.users.manage)
+func (c *Client) SetUserRole(ctx context.Context, userID uint, role string) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	body := map[string]string{"role": role}
+	if err := c.putJSON(ctx, idPath("/api/admin/users/%d/role", userID), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+// DisableUser blocks a user from logging in (admin only)
+func (c *Client) DisableUser(ctx context.Context, userID uint) (*User, error) {
+	return c.userAction(ctx, idPath("/api/admin/users/%d/disable", userID))
+}
+
+// EnableUser lets a disabled user log in again (admin only)
+func (c *Client) EnableUser(ctx context.Context, userID uint) (*User, error) {
+	return c.userAction(ctx, idPath("/api/admin/users/%d/enable", userID))
+}
+
+func (c *Client) userAction(ctx context.Context, path string) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	if err := c.postJSON(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+// ResetUserPassword sets a new password for a user (admin only)
+func (c *Client) ResetUserPassword(ctx context.Context, userID uint, newPassword string) error {
+	body := map[string]string{"new_password": newPassword}
+	return c.postJSON(ctx, idPath("/api/admin/users/%d/reset-password", userID), body, nil)
+}
+
+// DeleteUserTransfer deletes a user and hands their files to another user,
+// returning the new top-level folder that holds them (admin only)
+func (c *Client) DeleteUserTransfer(ctx context.Context, userID, transferTo uint) (*Folder, error) {
+	var resp struct {
+		Folder Folder `json:"folder"`
+	}
+	query := url.Values{"transfer_to": {strconv.FormatUint(uint64(transferTo), 10)}}
+	path := idPath("/api/admin/users/%d", userID) + "?" + query.Encode()
+	if err := c.deleteJSON(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Folder, nil
+}
+
+// DeleteUserPurge deletes a user together with all their files (admin only)
+func (c *Client) DeleteUserPurge(ctx context.Context, userID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/admin/users/%d", userID)+"?purge=true", nil, nil)
+}
+
+// BrowseUserFiles lists a folder of another user, or their root folder when
+// folderID is nil (admin only)
+func (c *Client) BrowseUserFiles(ctx context.Context, userID uint, folderID *uint) (*Listing, error) {
+	var listing Listing
+	query := url.Values{
+		"user_id":   {strconv.FormatUint(uint64(userID), 10)},
+		"folder_id": {folderParam(folderID)},
+	}
+	if err := c.getJSON(ctx, "/api/admin/files", query, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// Config returns the running server configuration (admin only)
+func (c *Client) Config(ctx context.Context) (*ServerConfig, error) {
+	var resp struct {
+		Config ServerConfig `json:"config"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/config", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Config, nil
+}
+
+// Maintenance returns the maintenance mode (admin.maintenance)
+func (c *Client) Maintenance(ctx context.Context) (*MaintenanceState, error) {
+	var resp struct {
+		Maintenance MaintenanceState `json:"maintenance"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/maintenance", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Maintenance, nil
+}
+
+// SetMaintenance turns maintenance mode on, with an optional message for
+// rejected clients, or off (admin.maintenance)
+func (c *Client) SetMaintenance(ctx context.Context, enabled bool, message string) (*MaintenanceState, error) {
+	var resp struct {
+		Maintenance MaintenanceState `json:"maintenance"`
+	}
+	body := map[string]interface{}{"enabled": enabled, "message": message}
+	if err := c.putJSON(ctx, "/api/admin/maintenance", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Maintenance, nil
+}
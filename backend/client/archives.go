@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ArchiveListing lists the members of a ZIP or tar file. Truncated is set
+// when the archive has more members than the server lists.
+type ArchiveListing struct {
+	File      File            `json:"file"`
+	Members   []ArchiveMember `json:"members"`
+	Truncated bool            `json:"truncated"`
+	TotalSize int64           `json:"total_size"`
+}
+
+// ExtractRequest names the destination of an extraction like a copy.
+// Members selects what to extract; a directory selects everything below it.
+type ExtractRequest struct {
+	TransferRequest
+	Members []string `json:"members,omitempty"`
+}
+
+// ListArchive lists the members of an archive file without extracting it
+func (c *Client) ListArchive(ctx context.Context, fileID uint) (*ArchiveListing, error) {
+	var listing ArchiveListing
+	if err := c.getJSON(ctx, idPath("/api/files/%d/archive", fileID), nil, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// DownloadArchiveMember opens one file inside an archive. The caller must
+// close the reader.
+func (c *Client) DownloadArchiveMember(ctx context.Context, fileID uint, path string) (io.ReadCloser, error) {
+	query := url.Values{"path": {path}}
+	return c.stream(ctx, http.MethodGet, idPath("/api/files/%d/archive/member", fileID)+"?"+query.Encode(), nil)
+}
+
+// ExtractArchive unpacks an archive file into a folder
+func (c *Client) ExtractArchive(ctx context.Context, fileID uint, req ExtractRequest) (*BatchUploadResult, error) {
+	var result BatchUploadResult
+	if err := c.postJSON(ctx, idPath("/api/files/%d/archive/extract", fileID), req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+)
+
+type authResponse struct {
+	Token string `json:"token"`
+	User  User   `json:"user"`
+}
+
+// Login authenticates with username and password and stores the returned
+// token on the client for subsequent requests
+func (c *Client) Login(ctx context.Context, username, password string) (*User, error) {
+	var resp authResponse
+	body := map[string]string{"username": username, "password": password}
+	if err := c.postJSON(ctx, "/api/auth/login", body, &resp); err != nil {
+		return nil, err
+	}
+	c.SetToken(resp.Token)
+	return &resp.User, nil
+}
+
+// Register creates an account and logs in as the new user
+func (c *Client) Register(ctx context.Context, username, email, password string) (*User, error) {
+	var resp authResponse
+	body := map[string]string{"username": username, "email": email, "password": password}
+	if err := c.postJSON(ctx, "/api/auth/register", body, &resp); err != nil {
+		return nil, err
+	}
+	c.SetToken(resp.Token)
+	return &resp.User, nil
+}
+
+// Me returns the authenticated user
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	if err := c.getJSON(ctx, "/api/auth/me", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+// Profile returns the authenticated user together with storage statistics
+func (c *Client) Profile(ctx context.Context) (*User, *ProfileStats, error) {
+	var resp struct {
+		User  User         `json:"user"`
+		Stats ProfileStats `json:"stats"`
+	}
+	if err := c.getJSON(ctx, "/api/profile", nil, &resp); err != nil {
+		return nil, nil, err
+	}
+	return &resp.User, &resp.Stats, nil
+}
+
+// UpdateProfile changes the username and/or email; empty values are left alone
+func (c *Client) UpdateProfile(ctx context.Context, username, email string) (*User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	body := map[string]string{"username": username, "email": email}
+	if err := c.putJSON(ctx, "/api/profile", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	body := map[string]string{"current_password": currentPassword, "new_password": newPassword}
+	return c.postJSON(ctx, "/api/profile/change-password", body, nil)
+}
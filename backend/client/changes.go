@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Changes returns up to limit change feed entries recorded after cursor.
+// A limit of 0 uses the server default.
+func (c *Client) Changes(ctx context.Context, cursor uint64, limit int) (*ChangesPage, error) {
+	query := url.Values{"cursor": {strconv.FormatUint(cursor, 10)}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var page ChangesPage
+	if err := c.getJSON(ctx, "/api/changes", query, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// LatestCursor returns the newest change feed cursor
+func (c *Client) LatestCursor(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Cursor uint64 `json:"cursor"`
+	}
+	if err := c.getJSON(ctx, "/api/changes/latest", nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Cursor, nil
+}
@@ -0,0 +1,280 @@
+// Package client is a typed Go client for the A-Drive REST API.
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.Login(ctx, "alice", "secret"); err != nil {
+//		return err
+//	}
+//	listing, err := c.ListFiles(ctx, nil)
+//
+// Every method takes a context for cancellation. Failed requests return an
+// *APIError, which can be matched against ErrNotFound, ErrUnauthorized and the
+// other sentinel errors with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Client talks to a single A-Drive server. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the default http.Client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken authenticates requests with an existing JWT instead of logging in
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the server URL the client was created with
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Token returns the JWT currently used for authentication
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken changes the JWT used for authentication
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// getJSON, postJSON, putJSON and deleteJSON send an optional JSON body and
+// decode a JSON response into out when it is not nil
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.doJSON(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) postJSON(ctx context.Context, path string, body, out interface{}) error {
+	return c.doJSON(ctx, http.MethodPost, path, body, out)
+}
+
+func (c *Client) putJSON(ctx context.Context, path string, body, out interface{}) error {
+	return c.doJSON(ctx, http.MethodPut, path, body, out)
+}
+
+func (c *Client) deleteJSON(ctx context.Context, path string, body, out interface{}) error {
+	return c.doJSON(ctx, http.MethodDelete, path, body, out)
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newJSONRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// stream performs a request with an optional JSON body and hands back the
+// open response body
+func (c *Client) stream(ctx context.Context, method, path string, body interface{}) (io.ReadCloser, error) {
+	req, err := c.newJSONRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// upload streams a multipart form with a single "file" part without
+// buffering the content in memory
+func (c *Client) upload(ctx context.Context, method, path string, fields map[string]string, name string, content io.Reader, out interface{}) error {
+	values := url.Values{}
+	for key, value := range fields {
+		values.Set(key, value)
+	}
+	return c.uploadParts(ctx, method, path, values, []formPart{{Field: "file", Name: name, Content: content}}, out)
+}
+
+// formPart is a file part of a multipart form
+type formPart struct {
+	Field   string
+	Name    string
+	Content io.Reader
+}
+
+// filePartHeader is the header of a file part. Unlike CreateFormFile it
+// sends the content type of the file's extension, which the server stores.
+func filePartHeader(field, name string) textproto.MIMEHeader {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(field), quoteEscaper.Replace(name)))
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// uploadParts streams a multipart form with any number of file parts
+func (c *Client) uploadParts(ctx context.Context, method, path string, fields url.Values, parts []formPart, out interface{}) error {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	go func() {
+		for key, values := range fields {
+			for _, value := range values {
+				if err := form.WriteField(key, value); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}
+		for _, p := range parts {
+			part, err := form.CreatePart(filePartHeader(p.Field, p.Name))
+			if err == nil {
+				_, err = io.Copy(part, p.Content)
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(form.Close())
+	}()
+
+	req, err := c.newRequest(ctx, method, path, pr)
+	if err != nil {
+		pr.Close()
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.send(req)
+	// Unblock the writer goroutine if the request stopped reading early
+	pr.Close()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) newJSONRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	if body == nil {
+		return c.newRequest(ctx, method, path, nil)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(ctx, method, path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// send executes the request and turns non-2xx responses into *APIError
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, newAPIError(req, resp)
+}
+
+func idPath(format string, ids ...uint) string {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// folderParam renders a folder reference the way the API expects, with nil
+// meaning the root folder
+func folderParam(folderID *uint) string {
+	if folderID == nil {
+		return "root"
+	}
+	return strconv.FormatUint(uint64(*folderID), 10)
+}
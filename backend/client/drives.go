@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+)
+
+// CreateDriveRequest describes a new team drive. QuotaBytes 0 means unlimited
+// and an empty DefaultRole means "contributor".
+type CreateDriveRequest struct {
+	Name        string `json:"name"`
+	GroupID     uint   `json:"group_id"`
+	QuotaBytes  int64  `json:"quota_bytes"`
+	DefaultRole string `json:"default_role,omitempty"`
+}
+
+// UpdateDriveRequest changes a team drive; empty fields are left alone
+type UpdateDriveRequest struct {
+	Name        string `json:"name,omitempty"`
+	QuotaBytes  *int64 `json:"quota_bytes,omitempty"`
+	DefaultRole string `json:"default_role,omitempty"`
+}
+
+// Drives returns the team drives the current user can access
+func (c *Client) Drives(ctx context.Context) ([]TeamDrive, error) {
+	var resp struct {
+		Drives []TeamDrive `json:"drives"`
+	}
+	if err := c.getJSON(ctx, "/api/drives", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Drives, nil
+}
+
+// CreateDrive creates a team drive for a group the user administers
+func (c *Client) CreateDrive(ctx context.Context, req CreateDriveRequest) (*TeamDrive, error) {
+	var resp struct {
+		Drive TeamDrive `json:"drive"`
+	}
+	if err := c.postJSON(ctx, "/api/drives", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Drive, nil
+}
+
+// GetDrive returns a team drive with the effective role of every member
+func (c *Client) GetDrive(ctx context.Context, driveID uint) (*TeamDrive, []DriveMember, error) {
+	var resp struct {
+		Drive   TeamDrive     `json:"drive"`
+		Members []DriveMember `json:"members"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/drives/%d", driveID), nil, &resp); err != nil {
+		return nil, nil, err
+	}
+	return &resp.Drive, resp.Members, nil
+}
+
+// UpdateDrive changes a team drive (manager)
+func (c *Client) UpdateDrive(ctx context.Context, driveID uint, req UpdateDriveRequest) (*TeamDrive, error) {
+	var resp struct {
+		Drive TeamDrive `json:"drive"`
+	}
+	if err := c.putJSON(ctx, idPath("/api/drives/%d", driveID), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Drive, nil
+}
+
+// DeleteDrive deletes an empty team drive (manager)
+func (c *Client) DeleteDrive(ctx context.Context, driveID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/drives/%d", driveID), nil, nil)
+}
+
+// SetDriveRole gives a group member a role other than the drive default (manager)
+func (c *Client) SetDriveRole(ctx context.Context, driveID, userID uint, role string) error {
+	body := map[string]string{"role": role}
+	return c.putJSON(ctx, idPath("/api/drives/%d/members/%d", driveID, userID), body, nil)
+}
+
+// ResetDriveRole makes the drive default apply to a member again (manager)
+func (c *Client) ResetDriveRole(ctx context.Context, driveID, userID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/drives/%d/members/%d", driveID, userID), nil, nil)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// UnmarshalJSON decodes the polymorphic "item" field into File or Folder
+func (f *Favorite) UnmarshalJSON(data []byte) error {
+	type plain Favorite
+	var raw struct {
+		plain
+		Item json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = Favorite(raw.plain)
+	return decodeItem(raw.Item, f.ItemType, &f.File, &f.Folder)
+}
+
+// Favorites lists the favorited items of the authenticated user, newest first
+func (c *Client) Favorites(ctx context.Context) ([]Favorite, error) {
+	var resp struct {
+		Favorites []Favorite `json:"favorites"`
+	}
+	if err := c.getJSON(ctx, "/api/favorites", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Favorites, nil
+}
+
+// AddFavorite favorites a file or folder; itemType is "file" or "folder"
+func (c *Client) AddFavorite(ctx context.Context, itemType string, itemID uint) (*Favorite, error) {
+	var resp struct {
+		Favorite Favorite `json:"favorite"`
+	}
+	body := map[string]interface{}{"item_type": itemType, "item_id": itemID}
+	if err := c.postJSON(ctx, "/api/favorites", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Favorite, nil
+}
+
+func (c *Client) RemoveFavorite(ctx context.Context, favoriteID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/favorites/%d", favoriteID), nil, nil)
+}
+
+// RemoveFavoriteItem unfavorites a file or folder by its own ID
+func (c *Client) RemoveFavoriteItem(ctx context.Context, itemType string, itemID uint) error {
+	body := map[string]interface{}{"item_type": itemType, "item_id": itemID}
+	return c.deleteJSON(ctx, "/api/favorites/item", body, nil)
+}
+
+// IsFavorite reports whether an item is favorited and returns the favorite ID if so
+func (c *Client) IsFavorite(ctx context.Context, itemType string, itemID uint) (bool, uint, error) {
+	var resp struct {
+		IsFavorite bool `json:"is_favorite"`
+		FavoriteID uint `json:"favorite_id"`
+	}
+	path := "/api/favorites/check/" + url.PathEscape(itemType) + idPath("/%d", itemID)
+	if err := c.getJSON(ctx, path, nil, &resp); err != nil {
+		return false, 0, err
+	}
+	return resp.IsFavorite, resp.FavoriteID, nil
+}
+
+// decodeItem fills file or folder from an embedded item depending on itemType
+func decodeItem(item json.RawMessage, itemType string, file **File, folder **Folder) error {
+	if len(item) == 0 || string(item) == "null" {
+		return nil
+	}
+	switch itemType {
+	case "file":
+		*file = new(File)
+		return json.Unmarshal(item, *file)
+	case "folder":
+		*folder = new(Folder)
+		return json.Unmarshal(item, *folder)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+// ListFiles returns the folders and files directly inside folderID, or inside
+// the root folder when folderID is nil
+func (c *Client) ListFiles(ctx context.Context, folderID *uint) (*Listing, error) {
+	var listing Listing
+	query := url.Values{"folder_id": {folderParam(folderID)}}
+	if err := c.getJSON(ctx, "/api/files", query, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// ListDriveFiles returns the folders and files directly inside folderID of a
+// team drive, or inside the drive root when folderID is nil
+func (c *Client) ListDriveFiles(ctx context.Context, driveID uint, folderID *uint) (*Listing, error) {
+	var listing Listing
+	query := url.Values{
+		"folder_id": {folderParam(folderID)},
+		"drive_id":  {strconv.FormatUint(uint64(driveID), 10)},
+	}
+	if err := c.getJSON(ctx, "/api/files", query, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// Upload streams content into a new file called name inside folderID
+func (c *Client) Upload(ctx context.Context, folderID *uint, name string, content io.Reader) (*File, error) {
+	file, _, err := c.UploadWithOptions(ctx, name, content, UploadOptions{FolderID: folderID})
+	return file, err
+}
+
+// UploadToDrive streams content into a new file called name inside folderID
+// of a team drive, or into the drive root when folderID is nil
+func (c *Client) UploadToDrive(ctx context.Context, driveID uint, folderID *uint, name string, content io.Reader) (*File, error) {
+	file, _, err := c.UploadWithOptions(ctx, name, content, UploadOptions{FolderID: folderID, DriveID: &driveID})
+	return file, err
+}
+
+// UploadOptions places an upload and decides what happens when the folder
+// already holds a file with the same name
+type UploadOptions struct {
+	FolderID   *uint
+	DriveID    *uint  // team drive for uploads to a drive root
+	OnConflict string // one of the Conflict policies; the server keeps both files when empty
+}
+
+// Upload results
+const (
+	UploadCreated  = "created"
+	UploadRenamed  = "renamed"  // stored as "name (1).ext" by ConflictKeepBoth
+	UploadReplaced = "replaced" // the existing file got the content, as a new version when versioned
+	UploadSkipped  = "skipped"  // the existing file is returned unchanged
+)
+
+// UploadWithOptions streams content into a file called name and reports which
+// of the Upload results happened
+func (c *Client) UploadWithOptions(ctx context.Context, name string, content io.Reader, opts UploadOptions) (*File, string, error) {
+	var resp struct {
+		File   File   `json:"file"`
+		Result string `json:"result"`
+	}
+	if err := c.uploadParts(ctx, http.MethodPost, "/api/files/upload", opts.fields(), []formPart{{Field: "file", Name: name, Content: content}}, &resp); err != nil {
+		return nil, "", err
+	}
+	return &resp.File, resp.Result, nil
+}
+
+func (opts UploadOptions) fields() url.Values {
+	fields := url.Values{"folder_id": {folderParam(opts.FolderID)}}
+	if opts.DriveID != nil {
+		fields.Set("drive_id", strconv.FormatUint(uint64(*opts.DriveID), 10))
+	}
+	if opts.OnConflict != "" {
+		fields.Set("on_conflict", opts.OnConflict)
+	}
+	return fields
+}
+
+// BatchFile is one file of UploadBatch
+type BatchFile struct {
+	Path    string // relative to the target folder, e.g. "photos/2024/beach.jpg"
+	Content io.Reader
+}
+
+// UploadBatch uploads files below opts.FolderID, creating the folders in
+// their paths that do not exist yet. OnConflict applies to every file.
+func (c *Client) UploadBatch(ctx context.Context, files []BatchFile, opts UploadOptions) (*BatchUploadResult, error) {
+	fields := opts.fields()
+	parts := make([]formPart, len(files))
+	for i, f := range files {
+		fields.Add("paths", f.Path)
+		parts[i] = formPart{Field: "files", Name: path.Base(f.Path), Content: f.Content}
+	}
+
+	var result BatchUploadResult
+	if err := c.uploadParts(ctx, http.MethodPost, "/api/files/upload/batch", fields, parts, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UploadArchive unpacks a ZIP or tar archive called name below opts.FolderID
+// without storing the archive itself
+func (c *Client) UploadArchive(ctx context.Context, name string, content io.Reader, opts UploadOptions) (*BatchUploadResult, error) {
+	var result BatchUploadResult
+	parts := []formPart{{Field: "archive", Name: name, Content: content}}
+	if err := c.uploadParts(ctx, http.MethodPost, "/api/files/upload/batch", opts.fields(), parts, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReplaceContent overwrites the content of an existing file. Versioned files
+// get a new version carrying comment.
+func (c *Client) ReplaceContent(ctx context.Context, fileID uint, name string, content io.Reader, comment string) (*File, error) {
+	var resp struct {
+		File File `json:"file"`
+	}
+	fields := map[string]string{}
+	if comment != "" {
+		fields["comment"] = comment
+	}
+	if err := c.upload(ctx, http.MethodPut, idPath("/api/files/%d/content", fileID), fields, name, content, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.File, nil
+}
+
+// Download opens the content of a file. The caller must close the reader.
+func (c *Client) Download(ctx context.Context, fileID uint) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, idPath("/api/files/%d/download", fileID), nil)
+}
+
+// Preview opens a file as served for viewing in a browser: Markdown and
+// source code as rendered HTML pages, or as plain text when raw is set. The
+// caller must close the reader.
+func (c *Client) Preview(ctx context.Context, fileID uint, raw bool) (io.ReadCloser, error) {
+	path := idPath("/api/files/%d/preview", fileID)
+	if raw {
+		path += "?format=raw"
+	}
+	return c.stream(ctx, http.MethodGet, path, nil)
+}
+
+func (c *Client) RenameFile(ctx context.Context, fileID uint, name string) (*File, error) {
+	var resp struct {
+		File File `json:"file"`
+	}
+	if err := c.putJSON(ctx, idPath("/api/files/%d", fileID), map[string]string{"name": name}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.File, nil
+}
+
+func (c *Client) DeleteFile(ctx context.Context, fileID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/files/%d", fileID), nil, nil)
+}
+
+// Photos returns every image file of the authenticated user, newest first
+func (c *Client) Photos(ctx context.Context) ([]File, error) {
+	var resp struct {
+		Files []File `json:"files"`
+	}
+	if err := c.getJSON(ctx, "/api/photos", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Files, nil
+}
+
+// FileTypes returns the MIME types in use grouped by category
+func (c *Client) FileTypes(ctx context.Context) (map[string][]string, error) {
+	var resp struct {
+		Categories map[string][]string `json:"categories"`
+	}
+	if err := c.getJSON(ctx, "/api/files/types", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Categories, nil
+}
+
+// Search looks up files and folders by name. query supports * and ?
+// wildcards; fileType optionally restricts files to "image", "document",
+// "video", "audio" or "archive".
+func (c *Client) Search(ctx context.Context, query, fileType string) (*SearchResult, error) {
+	params := url.Values{"q": {query}}
+	if fileType != "" {
+		params.Set("type", fileType)
+	}
+	var result SearchResult
+	if err := c.getJSON(ctx, "/api/search", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Name conflict policies for moves, copies and uploads. Without one a
+// conflict fails with 409.
+const (
+	ConflictReject    = "reject"    // fail with 409, the default for moves and copies
+	ConflictKeepBoth  = "keep_both" // rename the new item to "name (1)", the default for uploads
+	ConflictOverwrite = "overwrite" // replace the existing item
+	ConflictSkip      = "skip"      // leave the existing item alone
+)
+
+// TransferRequest names the destination of a move or copy. TargetFolderID nil
+// means the root of the personal space, or of the team drive DriveID.
+type TransferRequest struct {
+	TargetFolderID *uint  `json:"target_folder_id"`
+	DriveID        *uint  `json:"drive_id,omitempty"`
+	OnConflict     string `json:"on_conflict,omitempty"`
+}
+
+// MoveFile moves a file within its space. skipped reports that the
+// ConflictSkip policy left the existing file, which is returned, in place.
+func (c *Client) MoveFile(ctx context.Context, fileID uint, req TransferRequest) (file *File, skipped bool, err error) {
+	return c.transferFile(ctx, idPath("/api/files/%d/move", fileID), req)
+}
+
+// CopyFile copies a file into any folder the user can write to
+func (c *Client) CopyFile(ctx context.Context, fileID uint, req TransferRequest) (file *File, skipped bool, err error) {
+	return c.transferFile(ctx, idPath("/api/files/%d/copy", fileID), req)
+}
+
+func (c *Client) transferFile(ctx context.Context, path string, req TransferRequest) (*File, bool, error) {
+	var resp struct {
+		File    File `json:"file"`
+		Skipped bool `json:"skipped"`
+	}
+	if err := c.postJSON(ctx, path, req, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp.File, resp.Skipped, nil
+}
+
+type bulkRequest struct {
+	FileIDs   []uint `json:"file_ids"`
+	FolderIDs []uint `json:"folder_ids"`
+	Action    string `json:"action"`
+	TargetID  *uint  `json:"target_id,omitempty"`
+}
+
+// BulkDelete deletes several files and folders at once
+func (c *Client) BulkDelete(ctx context.Context, fileIDs, folderIDs []uint) (*BulkResult, error) {
+	var result BulkResult
+	req := bulkRequest{FileIDs: fileIDs, FolderIDs: folderIDs, Action: "delete"}
+	if err := c.postJSON(ctx, "/api/bulk", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkMove moves several files and folders into targetFolderID (0 for the root)
+func (c *Client) BulkMove(ctx context.Context, fileIDs, folderIDs []uint, targetFolderID uint) (*BulkResult, error) {
+	var result BulkResult
+	req := bulkRequest{FileIDs: fileIDs, FolderIDs: folderIDs, Action: "move", TargetID: &targetFolderID}
+	if err := c.postJSON(ctx, "/api/bulk", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkDownload streams a ZIP archive of the selected items. The caller must
+// close the reader.
+func (c *Client) BulkDownload(ctx context.Context, fileIDs, folderIDs []uint) (io.ReadCloser, error) {
+	req := bulkRequest{FileIDs: fileIDs, FolderIDs: folderIDs, Action: "download"}
+	return c.stream(ctx, http.MethodPost, "/api/bulk", req)
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// CreateFolderRequest describes a new folder; ParentID nil creates it at the
+// root of the personal space, or of the team drive DriveID
+type CreateFolderRequest struct {
+	Name      string `json:"name"`
+	ParentID  *uint  `json:"parent_id"`
+	DriveID   *uint  `json:"drive_id,omitempty"`
+	IconType  string `json:"icon_type,omitempty"`
+	IconColor string `json:"icon_color,omitempty"`
+}
+
+// UpdateFolderRequest changes a folder; empty fields are left alone
+type UpdateFolderRequest struct {
+	Name      string `json:"name,omitempty"`
+	IconType  string `json:"icon_type,omitempty"`
+	IconColor string `json:"icon_color,omitempty"`
+}
+
+func (c *Client) CreateFolder(ctx context.Context, req CreateFolderRequest) (*Folder, error) {
+	var resp struct {
+		Folder Folder `json:"folder"`
+	}
+	if err := c.postJSON(ctx, "/api/folders", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Folder, nil
+}
+
+// GetFolder returns a folder with its direct subfolders and files
+func (c *Client) GetFolder(ctx context.Context, folderID uint) (*Folder, error) {
+	var resp struct {
+		Folder Folder `json:"folder"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/folders/%d", folderID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Folder, nil
+}
+
+// Breadcrumbs returns the path from the root ("Home") down to folderID
+func (c *Client) Breadcrumbs(ctx context.Context, folderID uint) ([]Breadcrumb, error) {
+	var resp struct {
+		Breadcrumbs []Breadcrumb `json:"breadcrumbs"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/folders/%d/breadcrumbs", folderID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Breadcrumbs, nil
+}
+
+func (c *Client) UpdateFolder(ctx context.Context, folderID uint, req UpdateFolderRequest) (*Folder, error) {
+	var resp struct {
+		Folder Folder `json:"folder"`
+	}
+	if err := c.putJSON(ctx, idPath("/api/folders/%d", folderID), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Folder, nil
+}
+
+func (c *Client) DeleteFolder(ctx context.Context, folderID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/folders/%d", folderID), nil, nil)
+}
+
+// MoveFolder moves a folder tree within its space
+func (c *Client) MoveFolder(ctx context.Context, folderID uint, req TransferRequest) (folder *Folder, skipped bool, err error) {
+	return c.transferFolder(ctx, idPath("/api/folders/%d/move", folderID), req)
+}
+
+// CopyFolder copies a folder tree into any folder the user can write to
+func (c *Client) CopyFolder(ctx context.Context, folderID uint, req TransferRequest) (folder *Folder, skipped bool, err error) {
+	return c.transferFolder(ctx, idPath("/api/folders/%d/copy", folderID), req)
+}
+
+func (c *Client) transferFolder(ctx context.Context, path string, req TransferRequest) (*Folder, bool, error) {
+	var resp struct {
+		Folder  Folder `json:"folder"`
+		Skipped bool   `json:"skipped"`
+	}
+	if err := c.postJSON(ctx, path, req, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp.Folder, resp.Skipped, nil
+}
+
+// DownloadFolder streams a ZIP archive of a folder. The caller must close the reader.
+func (c *Client) DownloadFolder(ctx context.Context, folderID uint) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodPost, idPath("/api/folders/%d/zip", folderID), nil)
+}
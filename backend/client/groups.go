@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+)
+
+// Groups returns the groups the current user belongs to
+func (c *Client) Groups(ctx context.Context) ([]Group, error) {
+	var resp struct {
+		Groups []Group `json:"groups"`
+	}
+	if err := c.getJSON(ctx, "/api/groups", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Groups, nil
+}
+
+// CreateGroup creates a group with the current user as its admin
+func (c *Client) CreateGroup(ctx context.Context, name, description string) (*Group, error) {
+	var resp struct {
+		Group Group `json:"group"`
+	}
+	body := map[string]string{"name": name, "description": description}
+	if err := c.postJSON(ctx, "/api/groups", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Group, nil
+}
+
+// GetGroup returns a group with its members and the team drives it owns
+func (c *Client) GetGroup(ctx context.Context, groupID uint) (*Group, []TeamDrive, error) {
+	var resp struct {
+		Group  Group       `json:"group"`
+		Drives []TeamDrive `json:"drives"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/groups/%d", groupID), nil, &resp); err != nil {
+		return nil, nil, err
+	}
+	return &resp.Group, resp.Drives, nil
+}
+
+// UpdateGroup renames a group and sets its description (group admin)
+func (c *Client) UpdateGroup(ctx context.Context, groupID uint, name, description string) (*Group, error) {
+	var resp struct {
+		Group Group `json:"group"`
+	}
+	body := map[string]string{"name": name, "description": description}
+	if err := c.putJSON(ctx, idPath("/api/groups/%d", groupID), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Group, nil
+}
+
+// DeleteGroup deletes a group that owns no team drives (group admin)
+func (c *Client) DeleteGroup(ctx context.Context, groupID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/groups/%d", groupID), nil, nil)
+}
+
+// AddGroupMember adds a user to a group (group admin)
+func (c *Client) AddGroupMember(ctx context.Context, groupID, userID uint, isAdmin bool) (*GroupMember, error) {
+	var resp struct {
+		Member GroupMember `json:"member"`
+	}
+	body := map[string]interface{}{"user_id": userID, "is_admin": isAdmin}
+	if err := c.postJSON(ctx, idPath("/api/groups/%d/members", groupID), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Member, nil
+}
+
+// SetGroupAdmin grants or revokes group admin rights (group admin)
+func (c *Client) SetGroupAdmin(ctx context.Context, groupID, userID uint, isAdmin bool) (*GroupMember, error) {
+	var resp struct {
+		Member GroupMember `json:"member"`
+	}
+	body := map[string]bool{"is_admin": isAdmin}
+	if err := c.putJSON(ctx, idPath("/api/groups/%d/members/%d", groupID, userID), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Member, nil
+}
+
+// RemoveGroupMember removes a user from a group; members may remove themselves
+func (c *Client) RemoveGroupMember(ctx context.Context, groupID, userID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/groups/%d/members/%d", groupID, userID), nil, nil)
+}
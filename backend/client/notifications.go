@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// Notifications lists the notifications of the authenticated user, newest
+// first, and returns how many are unread
+func (c *Client) Notifications(ctx context.Context, unreadOnly bool) ([]Notification, int64, error) {
+	var resp struct {
+		Notifications []Notification `json:"notifications"`
+		Unread        int64          `json:"unread"`
+	}
+	var query url.Values
+	if unreadOnly {
+		query = url.Values{"unread": {"true"}}
+	}
+	if err := c.getJSON(ctx, "/api/notifications", query, &resp); err != nil {
+		return nil, 0, err
+	}
+	return resp.Notifications, resp.Unread, nil
+}
+
+func (c *Client) MarkNotificationRead(ctx context.Context, id uint) (*Notification, error) {
+	var resp struct {
+		Notification Notification `json:"notification"`
+	}
+	if err := c.postJSON(ctx, idPath("/api/notifications/%d/read", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Notification, nil
+}
+
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) error {
+	return c.postJSON(ctx, "/api/notifications/read-all", nil, nil)
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// UnmarshalJSON decodes the polymorphic "item" field into File or Folder
+func (r *RecentItem) UnmarshalJSON(data []byte) error {
+	type plain RecentItem
+	var raw struct {
+		plain
+		Item json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = RecentItem(raw.plain)
+	return decodeItem(raw.Item, r.ItemType, &r.File, &r.Folder)
+}
+
+// RecentFiles returns the 20 most recently accessed files and folders
+func (c *Client) RecentFiles(ctx context.Context) ([]RecentItem, error) {
+	var resp struct {
+		RecentFiles []RecentItem `json:"recent_files"`
+	}
+	if err := c.getJSON(ctx, "/api/recent-files", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.RecentFiles, nil
+}
+
+func (c *Client) TrackFileAccess(ctx context.Context, fileID uint) error {
+	return c.postJSON(ctx, idPath("/api/recent-files/track/file/%d", fileID), nil, nil)
+}
+
+func (c *Client) TrackFolderAccess(ctx context.Context, folderID uint) error {
+	return c.postJSON(ctx, idPath("/api/recent-files/track/folder/%d", folderID), nil, nil)
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+)
+
+// Permissions returns every permission roles can grant (admin.roles.manage)
+func (c *Client) Permissions(ctx context.Context) ([]PermissionInfo, error) {
+	var resp struct {
+		Permissions []PermissionInfo `json:"permissions"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/permissions", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+// Roles returns every role with the number of users holding it (admin.roles.manage)
+func (c *Client) Roles(ctx context.Context) ([]Role, error) {
+	var resp struct {
+		Roles []Role `json:"roles"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/roles", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+// CreateRole defines a role granting the given permissions (admin.roles.manage)
+func (c *Client) CreateRole(ctx context.Context, name, description string, permissions []string) (*Role, error) {
+	var resp struct {
+		Role Role `json:"role"`
+	}
+	body := map[string]interface{}{"name": name, "description": description, "permissions": permissions}
+	if err := c.postJSON(ctx, "/api/admin/roles", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Role, nil
+}
+
+// SetRolePermissions replaces the permissions of a role (admin.roles.manage)
+func (c *Client) SetRolePermissions(ctx context.Context, roleID uint, permissions []string) (*Role, error) {
+	var resp struct {
+		Role Role `json:"role"`
+	}
+	body := map[string][]string{"permissions": permissions}
+	if err := c.putJSON(ctx, idPath("/api/admin/roles/%d", roleID), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Role, nil
+}
+
+// DeleteRole deletes a custom role nobody holds (admin.roles.manage)
+func (c *Client) DeleteRole(ctx context.Context, roleID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/admin/roles/%d", roleID), nil, nil)
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateShare creates a share link for a file and returns it with its public URL
+func (c *Client) CreateShare(ctx context.Context, fileID uint, req CreateShareRequest) (*Share, string, error) {
+	var resp struct {
+		Share    Share  `json:"share"`
+		ShareURL string `json:"share_url"`
+	}
+	if err := c.postJSON(ctx, idPath("/api/files/%d/share", fileID), req, &resp); err != nil {
+		return nil, "", err
+	}
+	return &resp.Share, resp.ShareURL, nil
+}
+
+// CreateFolderShare creates a share link for a folder and everything inside it
+// and returns it with its public URL
+func (c *Client) CreateFolderShare(ctx context.Context, folderID uint, req CreateShareRequest) (*Share, string, error) {
+	var resp struct {
+		Share    Share  `json:"share"`
+		ShareURL string `json:"share_url"`
+	}
+	if err := c.postJSON(ctx, idPath("/api/folders/%d/share", folderID), req, &resp); err != nil {
+		return nil, "", err
+	}
+	return &resp.Share, resp.ShareURL, nil
+}
+
+// FileShares lists the share links of a file. ShareToken holds the full share URL.
+func (c *Client) FileShares(ctx context.Context, fileID uint) ([]Share, error) {
+	var resp struct {
+		Shares []Share `json:"shares"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/files/%d/shares", fileID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// FolderShares lists the share links of a folder. ShareToken holds the full
+// share URL.
+func (c *Client) FolderShares(ctx context.Context, folderID uint) ([]Share, error) {
+	var resp struct {
+		Shares []Share `json:"shares"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/folders/%d/shares", folderID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// Shares lists every share link created by the authenticated user. ShareToken
+// holds the full share URL.
+func (c *Client) Shares(ctx context.Context) ([]Share, error) {
+	var resp struct {
+		Shares []Share `json:"shares"`
+	}
+	if err := c.getJSON(ctx, "/api/shares", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// UpdateShare changes the settings of a share and returns it with its URL
+func (c *Client) UpdateShare(ctx context.Context, shareID uint, update ShareUpdate) (*Share, string, error) {
+	return c.shareAction(ctx, http.MethodPut, idPath("/api/shares/%d", shareID), update)
+}
+
+// DisableShare turns a share link off without deleting it
+func (c *Client) DisableShare(ctx context.Context, shareID uint) (*Share, error) {
+	share, _, err := c.shareAction(ctx, http.MethodPost, idPath("/api/shares/%d/disable", shareID), nil)
+	return share, err
+}
+
+// EnableShare turns a disabled share link back on
+func (c *Client) EnableShare(ctx context.Context, shareID uint) (*Share, error) {
+	share, _, err := c.shareAction(ctx, http.MethodPost, idPath("/api/shares/%d/enable", shareID), nil)
+	return share, err
+}
+
+func (c *Client) shareAction(ctx context.Context, method, path string, body interface{}) (*Share, string, error) {
+	var resp struct {
+		Share    Share  `json:"share"`
+		ShareURL string `json:"share_url"`
+	}
+	if err := c.doJSON(ctx, method, path, body, &resp); err != nil {
+		return nil, "", err
+	}
+	return &resp.Share, resp.ShareURL, nil
+}
+
+func (c *Client) DeleteShare(ctx context.Context, shareID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/shares/%d", shareID), nil, nil)
+}
+
+// ShareAnalytics returns the access analytics of a share for the last days
+// days, including today. days of 0 uses the server default of 30.
+func (c *Client) ShareAnalytics(ctx context.Context, shareID uint, days int) (*ShareAnalytics, error) {
+	var resp struct {
+		Analytics ShareAnalytics `json:"analytics"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/shares/%d/analytics", shareID), analyticsQuery(days), &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Analytics, nil
+}
+
+// AllShareAnalytics returns the access analytics of all shares of the
+// authenticated user and the totals of each share
+func (c *Client) AllShareAnalytics(ctx context.Context, days int) (*ShareAnalytics, []ShareStats, error) {
+	var resp struct {
+		Analytics ShareAnalytics `json:"analytics"`
+		Shares    []ShareStats   `json:"shares"`
+	}
+	if err := c.getJSON(ctx, "/api/shares/analytics", analyticsQuery(days), &resp); err != nil {
+		return nil, nil, err
+	}
+	return &resp.Analytics, resp.Shares, nil
+}
+
+// ExportShareAccesses opens the accesses of a share as CSV. shareID 0 exports
+// the accesses of all shares of the authenticated user. The caller must close
+// the reader.
+func (c *Client) ExportShareAccesses(ctx context.Context, shareID uint, days int) (io.ReadCloser, error) {
+	path := "/api/shares/analytics/export"
+	if shareID != 0 {
+		path = idPath("/api/shares/%d/analytics/export", shareID)
+	}
+	if query := analyticsQuery(days); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.stream(ctx, http.MethodGet, path, nil)
+}
+
+func analyticsQuery(days int) url.Values {
+	if days <= 0 {
+		return nil
+	}
+	return url.Values{"days": {strconv.Itoa(days)}}
+}
+
+// OpenShare accesses a share link as an anonymous recipient. password is only
+// needed for password-protected shares.
+func (c *Client) OpenShare(ctx context.Context, token, password string) (*SharedFile, error) {
+	var shared SharedFile
+	path := "/share/" + url.PathEscape(token) + "/access"
+	if err := c.postJSON(ctx, path, map[string]string{"password": password}, &shared); err != nil {
+		return nil, err
+	}
+	return &shared, nil
+}
+
+// BrowseShare lists a folder inside a folder share, the shared folder itself
+// when folderID is 0. session is as for DownloadShare.
+func (c *Client) BrowseShare(ctx context.Context, token, session string, folderID uint) (*SharedFile, error) {
+	query := url.Values{}
+	if folderID != 0 {
+		query.Set("folder", strconv.FormatUint(uint64(folderID), 10))
+	}
+	if session != "" {
+		query.Set("session", session)
+	}
+	var shared SharedFile
+	if err := c.getJSON(ctx, "/share/"+url.PathEscape(token), query, &shared); err != nil {
+		return nil, err
+	}
+	return &shared, nil
+}
+
+// DownloadShare opens the content behind a share link, a zip of the folder
+// for folder shares. session is the SessionToken from OpenShare for
+// password-protected shares and empty otherwise. The caller must close the
+// reader.
+func (c *Client) DownloadShare(ctx context.Context, token, session string) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, sharePath(token, "download", session), nil)
+}
+
+// PreviewShare opens the content of a share that allows previews, as served
+// for viewing in a browser. The caller must close the reader.
+func (c *Client) PreviewShare(ctx context.Context, token, session string) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, sharePath(token, "preview", session), nil)
+}
+
+// DownloadSharedFolderFile opens a file inside a folder share. The caller
+// must close the reader.
+func (c *Client) DownloadSharedFolderFile(ctx context.Context, token, session string, fileID uint) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, sharePath(token, idPath("files/%d/download", fileID), session), nil)
+}
+
+// PreviewSharedFolderFile opens a file inside a folder share that allows
+// previews. The caller must close the reader.
+func (c *Client) PreviewSharedFolderFile(ctx context.Context, token, session string, fileID uint) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, sharePath(token, idPath("files/%d/preview", fileID), session), nil)
+}
+
+func sharePath(token, action, session string) string {
+	path := "/share/" + url.PathEscape(token) + "/" + action
+	if session != "" {
+		path += "?" + url.Values{"session": {session}}.Encode()
+	}
+	return path
+}
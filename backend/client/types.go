@@ -0,0 +1,401 @@
+package client
+
+import (
+	"time"
+)
+
+// User is an account as returned by the auth, profile and admin endpoints
+type User struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Permissions granted by the role; only filled in by Me
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+type Folder struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	ParentID   *uint     `json:"parent_id"`
+	DriveID    *uint     `json:"drive_id"`
+	UserID     uint      `json:"user_id"`
+	IconType   string    `json:"icon_type"`
+	IconColor  string    `json:"icon_color"`
+	Path       string    `json:"path"`
+	IsFavorite bool      `json:"is_favorite"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Subfolders []Folder `json:"subfolders,omitempty"`
+	Files      []File   `json:"files,omitempty"`
+}
+
+type File struct {
+	ID                uint      `json:"id"`
+	Name              string    `json:"name"`
+	OriginalName      string    `json:"original_name"`
+	FolderID          *uint     `json:"folder_id"`
+	DriveID           *uint     `json:"drive_id"`
+	UserID            uint      `json:"user_id"`
+	Size              int64     `json:"size"`
+	MimeType          string    `json:"mime_type"`
+	Checksum          string    `json:"checksum"`
+	CurrentVersion    int       `json:"current_version"`
+	VersioningEnabled bool      `json:"versioning_enabled"`
+	IsFavorite        bool      `json:"is_favorite"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Listing is the content of a single folder
+type Listing struct {
+	Folders []Folder `json:"folders"`
+	Files   []File   `json:"files"`
+}
+
+type Breadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type SearchResult struct {
+	Files   []File   `json:"files"`
+	Folders []Folder `json:"folders"`
+	Total   int      `json:"total"`
+}
+
+// BulkResult reports the outcome of a bulk delete or move
+type BulkResult struct {
+	Success     bool     `json:"success"`
+	Message     string   `json:"message"`
+	Processed   int      `json:"processed"`
+	Failed      int      `json:"failed"`
+	FailedItems []string `json:"failed_items,omitempty"`
+}
+
+// BatchUploadResult reports a batch upload with the outcome of every file
+type BatchUploadResult struct {
+	BulkResult
+	FoldersCreated int               `json:"folders_created"`
+	Items          []BatchUploadItem `json:"items"`
+}
+
+// ArchiveMember is one entry of an archive
+type ArchiveMember struct {
+	Path     string    `json:"path"`
+	Dir      bool      `json:"dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// BatchUploadItem is one file of a batch upload. Result is one of the Upload
+// results, or empty with Error set when the file failed.
+type BatchUploadItem struct {
+	Path   string `json:"path"`
+	Result string `json:"result,omitempty"`
+	File   *File  `json:"file,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type FileVersion struct {
+	ID            uint      `json:"id"`
+	FileID        uint      `json:"file_id"`
+	Version       int       `json:"version"`
+	Size          int64     `json:"size"`
+	Checksum      string    `json:"checksum"`
+	Chunked       bool      `json:"chunked"`
+	Comment       string    `json:"comment"`
+	Label         string    `json:"label"`
+	Pinned        bool      `json:"pinned"`
+	CreatedBy     uint      `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedByUser *User     `json:"created_by_user,omitempty"`
+}
+
+// VersionStats compares the size of old versions with the bytes stored for
+// them. Chunked versions share unchanged chunks, so PhysicalBytes can be far
+// below LogicalBytes.
+type VersionStats struct {
+	Versions        int64 `json:"versions"`
+	ChunkedVersions int64 `json:"chunked_versions"`
+	Chunks          int64 `json:"chunks"`
+	LogicalBytes    int64 `json:"logical_bytes"`
+	PhysicalBytes   int64 `json:"physical_bytes"`
+}
+
+// VersioningRules turn on automatic versioning and decide which old versions
+// are kept. Zero keep rules keep every old version.
+type VersioningRules struct {
+	Enabled         bool  `json:"enabled"`
+	KeepLast        int   `json:"keep_last"`
+	KeepDailyDays   int   `json:"keep_daily_days"`
+	KeepWeeklyWeeks int   `json:"keep_weekly_weeks"`
+	MaxTotalBytes   int64 `json:"max_total_bytes"`
+}
+
+// VersioningPolicy applies VersioningRules to a folder tree, or to all
+// personal files of a user
+type VersioningPolicy struct {
+	ID       uint  `json:"id"`
+	FolderID *uint `json:"folder_id"`
+	UserID   *uint `json:"user_id"`
+	VersioningRules
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Share struct {
+	ID                    uint       `json:"id"`
+	FileID                uint       `json:"file_id"` // 0 for folder shares
+	File                  *File      `json:"file,omitempty"`
+	FolderID              uint       `json:"folder_id"` // 0 for file shares
+	Folder                *Folder    `json:"folder,omitempty"`
+	SharedBy              uint       `json:"shared_by"`
+	SharedByUser          *User      `json:"shared_by_user,omitempty"`
+	ShareToken            string     `json:"share_token"`
+	Slug                  *string    `json:"slug"`
+	ShareType             string     `json:"share_type"`
+	ExpiresAt             *time.Time `json:"expires_at"`
+	DownloadCount         int        `json:"download_count"`
+	MaxDownloads          *int       `json:"max_downloads"`
+	AllowPreview          bool       `json:"allow_preview"`
+	NotifyOnFirstDownload bool       `json:"notify_on_first_download"`
+	FirstDownloadedAt     *time.Time `json:"first_downloaded_at"`
+	Disabled              bool       `json:"disabled"`
+	Status                string     `json:"status"` // "active", "expired" or "exhausted"
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// CreateShareRequest describes a new share link. ShareType is "public",
+// "password" or "private".
+type CreateShareRequest struct {
+	ShareType    string     `json:"share_type"`
+	Password     string     `json:"password,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	MaxDownloads *int       `json:"max_downloads,omitempty"`
+	AllowPreview bool       `json:"allow_preview"`
+
+	// NotifyOnFirstDownload adds a notification for the creator when the
+	// share is downloaded for the first time
+	NotifyOnFirstDownload bool `json:"notify_on_first_download"`
+
+	// Slug is an optional readable name used in the share URL in place of
+	// the generated token
+	Slug string `json:"slug,omitempty"`
+}
+
+// ShareUpdate changes the settings of a share; nil fields are left unchanged.
+// MaxDownloads of 0 removes the limit and an empty Slug removes the slug.
+type ShareUpdate struct {
+	ShareType             *string    `json:"share_type,omitempty"`
+	Password              *string    `json:"password,omitempty"`
+	ExpiresAt             *time.Time `json:"expires_at,omitempty"`
+	RemoveExpiry          bool       `json:"remove_expiry,omitempty"`
+	MaxDownloads          *int       `json:"max_downloads,omitempty"`
+	AllowPreview          *bool      `json:"allow_preview,omitempty"`
+	NotifyOnFirstDownload *bool      `json:"notify_on_first_download,omitempty"`
+	Slug                  *string    `json:"slug,omitempty"`
+}
+
+// ShareAnalytics summarizes the accesses of shares since From
+type ShareAnalytics struct {
+	From    time.Time `json:"from"`
+	Summary struct {
+		Views          int64      `json:"views"`
+		Downloads      int64      `json:"downloads"`
+		Previews       int64      `json:"previews"`
+		UniqueVisitors int64      `json:"unique_visitors"`
+		FirstAccessAt  *time.Time `json:"first_access_at"`
+		LastAccessAt   *time.Time `json:"last_access_at"`
+	} `json:"summary"`
+	Timeline []struct {
+		Date      string `json:"date"` // YYYY-MM-DD, UTC
+		Views     int64  `json:"views"`
+		Downloads int64  `json:"downloads"`
+		Previews  int64  `json:"previews"`
+	} `json:"timeline"`
+	UserAgents []AccessCount `json:"user_agents"`
+	Referrers  []AccessCount `json:"referrers"`
+	Countries  []AccessCount `json:"countries"`
+}
+
+// AccessCount is one entry of a top list of ShareAnalytics
+type AccessCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ShareStats are the totals of one share in the analytics of all shares
+type ShareStats struct {
+	ShareID        uint   `json:"share_id"`
+	FileID         uint   `json:"file_id"`   // 0 for folder shares
+	FolderID       uint   `json:"folder_id"` // 0 for file shares
+	FileName       string `json:"file_name"` // the folder name for folder shares
+	ShareType      string `json:"share_type"`
+	Views          int64  `json:"views"`
+	Downloads      int64  `json:"downloads"`
+	Previews       int64  `json:"previews"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// Notification tells the user about something that happened to their items
+type Notification struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	FileID    *uint      `json:"file_id"`
+	ShareID   *uint      `json:"share_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// SharedFile is what an anonymous recipient sees when opening a share link.
+// File shares set File; folder shares set Folder, the shared folder, and list
+// the contents of CurrentFolder.
+type SharedFile struct {
+	ID            uint      `json:"id"`
+	File          *File     `json:"file,omitempty"`
+	Folder        *Folder   `json:"folder,omitempty"`
+	CurrentFolder *Folder   `json:"current_folder,omitempty"`
+	Folders       []Folder  `json:"folders,omitempty"`
+	Files         []File    `json:"files,omitempty"`
+	SharedBy      string    `json:"shared_by"`
+	ShareType     string    `json:"share_type"`
+	AllowPreview  bool      `json:"allow_preview"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Set when a password unlocked the share; pass SessionToken to
+	// DownloadShare and PreviewShare
+	SessionToken     string     `json:"session_token,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
+}
+
+// Favorite is a favorited item; exactly one of File and Folder is set
+type Favorite struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	ItemType  string    `json:"item_type"`
+	ItemID    uint      `json:"item_id"`
+	CreatedAt time.Time `json:"created_at"`
+	File      *File     `json:"-"`
+	Folder    *Folder   `json:"-"`
+}
+
+// RecentItem is a recently accessed item; exactly one of File and Folder is set
+type RecentItem struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	ItemType   string    `json:"item_type"`
+	ItemID     uint      `json:"item_id"`
+	AccessedAt time.Time `json:"accessed_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	File       *File     `json:"-"`
+	Folder     *Folder   `json:"-"`
+}
+
+// Change is an entry of the change feed
+type Change struct {
+	Cursor    uint64    `json:"cursor"`
+	UserID    uint      `json:"user_id"`
+	DriveID   *uint     `json:"drive_id"`
+	ItemType  string    `json:"item_type"`
+	ItemID    uint      `json:"item_id"`
+	Action    string    `json:"action"`
+	Name      string    `json:"name"`
+	ParentID  *uint     `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChangesPage is one page of the change feed
+type ChangesPage struct {
+	Changes []Change `json:"changes"`
+	Cursor  uint64   `json:"cursor"`
+	HasMore bool     `json:"has_more"`
+}
+
+type ProfileStats struct {
+	TotalFiles   int    `json:"total_files"`
+	TotalFolders int    `json:"total_folders"`
+	TotalSize    int64  `json:"total_size"`
+	LastActivity string `json:"last_activity"`
+}
+
+type ServerConfig struct {
+	CORSOrigins []string `json:"cors_origins"`
+	CORSMethods []string `json:"cors_methods"`
+	CORSHeaders []string `json:"cors_headers"`
+	Port        string   `json:"port"`
+	MaxFileSize int64    `json:"max_file_size"`
+}
+
+type MaintenanceState struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+type Group struct {
+	ID          uint          `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	CreatedBy   uint          `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Members     []GroupMember `json:"members,omitempty"`
+}
+
+type GroupMember struct {
+	ID      uint `json:"id"`
+	GroupID uint `json:"group_id"`
+	UserID  uint `json:"user_id"`
+	IsAdmin bool `json:"is_admin"`
+	User    User `json:"user"`
+}
+
+// TeamDrive is a space owned by a group. Role is the current user's role.
+type TeamDrive struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	GroupID     uint      `json:"group_id"`
+	QuotaBytes  int64     `json:"quota_bytes"`
+	DefaultRole string    `json:"default_role"`
+	Role        string    `json:"role"`
+	UsedBytes   int64     `json:"used_bytes"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Group       Group     `json:"group"`
+}
+
+// DriveMember is a group member with their effective role in a team drive
+type DriveMember struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Override bool   `json:"override"`
+}
+
+// Role is a named bundle of permissions assigned to users
+type Role struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	UserCount   int64     `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PermissionInfo describes a permission that roles can grant
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CurrentVersion refers to the current content of a file in a diff
+const CurrentVersion = "current"
+
+// Diff modes
+const (
+	DiffUnified    = "unified"
+	DiffSideBySide = "side_by_side"
+)
+
+// DiffOptions selects the versions to compare by ID, or CurrentVersion. To
+// defaults to the current content and Mode to DiffUnified. Context is the
+// number of unified context lines, the server default when nil.
+type DiffOptions struct {
+	From    string
+	To      string
+	Mode    string
+	Context *int
+}
+
+// DiffSide identifies one side of a comparison
+type DiffSide struct {
+	VersionID *uint  `json:"version_id"`
+	Version   int    `json:"version"`
+	Current   bool   `json:"current"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+}
+
+// DiffLine is one line of a line diff. Op is equal, insert or delete; the
+// line numbers are zero on the side the line does not exist in.
+type DiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line"`
+	NewLine int    `json:"new_line"`
+	Text    string `json:"text"`
+}
+
+// DiffCell is one side of a side-by-side row
+type DiffCell struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// DiffRow is one row of a side-by-side diff. Op is equal, change, insert or
+// delete; Left is nil for inserts and Right for deletes.
+type DiffRow struct {
+	Op    string    `json:"op"`
+	Left  *DiffCell `json:"left"`
+	Right *DiffCell `json:"right"`
+}
+
+// VersionDiff compares two versions. Unified and Lines are set in the
+// unified mode, Format and Rows in the side-by-side mode.
+type VersionDiff struct {
+	File      File       `json:"file"`
+	From      DiffSide   `json:"from"`
+	To        DiffSide   `json:"to"`
+	Mode      string     `json:"mode"`
+	Identical bool       `json:"identical"`
+	Unified   string     `json:"unified"`
+	Lines     []DiffLine `json:"lines"`
+	Format    string     `json:"format"` // json, yaml or text
+	Rows      []DiffRow  `json:"rows"`
+}
+
+// EnableVersioning starts keeping versions of a file, recording its current
+// content as version 1
+func (c *Client) EnableVersioning(ctx context.Context, fileID uint) error {
+	return c.postJSON(ctx, idPath("/api/files/%d/versioning/enable", fileID), nil, nil)
+}
+
+// DisableVersioning stops versioning and deletes every old version
+func (c *Client) DisableVersioning(ctx context.Context, fileID uint) error {
+	return c.postJSON(ctx, idPath("/api/files/%d/versioning/disable", fileID), nil, nil)
+}
+
+// Versions lists the versions of a file, newest first
+func (c *Client) Versions(ctx context.Context, fileID uint) ([]FileVersion, error) {
+	var resp struct {
+		Versions []FileVersion `json:"versions"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/files/%d/versions", fileID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
+
+// CreateVersion uploads content as the new current version of a file
+func (c *Client) CreateVersion(ctx context.Context, fileID uint, name string, content io.Reader, comment string) (*FileVersion, error) {
+	var resp struct {
+		Version FileVersion `json:"version"`
+	}
+	fields := map[string]string{"comment": comment}
+	if err := c.upload(ctx, http.MethodPost, idPath("/api/files/%d/versions", fileID), fields, name, content, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Version, nil
+}
+
+// RestoreVersion makes an older version the current content of the file. The
+// restored content is recorded as a new version, which is returned.
+func (c *Client) RestoreVersion(ctx context.Context, fileID, versionID uint) (*FileVersion, error) {
+	var resp struct {
+		Version FileVersion `json:"version"`
+	}
+	if err := c.postJSON(ctx, idPath("/api/files/%d/versions/%d/restore", fileID, versionID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Version, nil
+}
+
+// VersionUpdate edits the metadata of a version. Nil fields are left unchanged.
+type VersionUpdate struct {
+	Comment *string `json:"comment,omitempty"`
+	Label   *string `json:"label,omitempty"`
+	Pinned  *bool   `json:"pinned,omitempty"`
+}
+
+// UpdateVersion edits the comment or label of a version, or pins it so that
+// pruning never removes it
+func (c *Client) UpdateVersion(ctx context.Context, fileID, versionID uint, update VersionUpdate) (*FileVersion, error) {
+	var resp struct {
+		Version FileVersion `json:"version"`
+	}
+	if err := c.putJSON(ctx, idPath("/api/files/%d/versions/%d", fileID, versionID), update, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Version, nil
+}
+
+// DeleteVersion removes an old version. Pinned versions must be unpinned first.
+func (c *Client) DeleteVersion(ctx context.Context, fileID, versionID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/files/%d/versions/%d", fileID, versionID), nil, nil)
+}
+
+// DownloadVersion opens the content of a version. The caller must close the reader.
+func (c *Client) DownloadVersion(ctx context.Context, fileID, versionID uint) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, idPath("/api/files/%d/versions/%d/download", fileID, versionID), nil)
+}
+
+// EffectiveVersioningPolicy returns the policy that applies to a file, nil
+// when none does
+func (c *Client) EffectiveVersioningPolicy(ctx context.Context, fileID uint) (*VersioningPolicy, error) {
+	return c.getPolicy(ctx, idPath("/api/files/%d/versioning", fileID))
+}
+
+// VersioningPolicy returns the user's default policy for personal files, nil
+// when none is set
+func (c *Client) VersioningPolicy(ctx context.Context) (*VersioningPolicy, error) {
+	return c.getPolicy(ctx, "/api/versioning/policy")
+}
+
+// SetVersioningPolicy sets the user's default policy for personal files
+func (c *Client) SetVersioningPolicy(ctx context.Context, rules VersioningRules) (*VersioningPolicy, error) {
+	return c.putPolicy(ctx, "/api/versioning/policy", rules)
+}
+
+// DeleteVersioningPolicy removes the user's default policy
+func (c *Client) DeleteVersioningPolicy(ctx context.Context) error {
+	return c.deleteJSON(ctx, "/api/versioning/policy", nil, nil)
+}
+
+// FolderVersioningPolicy returns the policy set on a folder itself, nil when
+// none is set
+func (c *Client) FolderVersioningPolicy(ctx context.Context, folderID uint) (*VersioningPolicy, error) {
+	return c.getPolicy(ctx, idPath("/api/folders/%d/versioning", folderID))
+}
+
+// SetFolderVersioningPolicy sets the policy of a folder and its subtree
+func (c *Client) SetFolderVersioningPolicy(ctx context.Context, folderID uint, rules VersioningRules) (*VersioningPolicy, error) {
+	return c.putPolicy(ctx, idPath("/api/folders/%d/versioning", folderID), rules)
+}
+
+// DeleteFolderVersioningPolicy removes the policy of a folder
+func (c *Client) DeleteFolderVersioningPolicy(ctx context.Context, folderID uint) error {
+	return c.deleteJSON(ctx, idPath("/api/folders/%d/versioning", folderID), nil, nil)
+}
+
+func (c *Client) getPolicy(ctx context.Context, path string) (*VersioningPolicy, error) {
+	var resp struct {
+		Policy *VersioningPolicy `json:"policy"`
+	}
+	if err := c.getJSON(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Policy, nil
+}
+
+func (c *Client) putPolicy(ctx context.Context, path string, rules VersioningRules) (*VersioningPolicy, error) {
+	var resp struct {
+		Policy VersioningPolicy `json:"policy"`
+	}
+	if err := c.putJSON(ctx, path, rules, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Policy, nil
+}
+
+// DiffVersions compares two versions of a text file
+func (c *Client) DiffVersions(ctx context.Context, fileID uint, opts DiffOptions) (*VersionDiff, error) {
+	query := url.Values{"from": {opts.From}}
+	if opts.To != "" {
+		query.Set("to", opts.To)
+	}
+	if opts.Mode != "" {
+		query.Set("mode", opts.Mode)
+	}
+	if opts.Context != nil {
+		query.Set("context", strconv.Itoa(*opts.Context))
+	}
+
+	var diff VersionDiff
+	if err := c.getJSON(ctx, idPath("/api/files/%d/versions/diff", fileID), query, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// FileVersionStats returns the version storage statistics of one file
+func (c *Client) FileVersionStats(ctx context.Context, fileID uint) (*VersionStats, error) {
+	var resp struct {
+		Stats VersionStats `json:"stats"`
+	}
+	if err := c.getJSON(ctx, idPath("/api/files/%d/versions/stats", fileID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Stats, nil
+}
+
+// VersioningStats returns the version storage statistics of the personal
+// space, or of a team drive when driveID is set
+func (c *Client) VersioningStats(ctx context.Context, driveID *uint) (*VersionStats, error) {
+	var resp struct {
+		Stats VersionStats `json:"stats"`
+	}
+	query := url.Values{}
+	if driveID != nil {
+		query.Set("drive_id", strconv.FormatUint(uint64(*driveID), 10))
+	}
+	if err := c.getJSON(ctx, "/api/versioning/stats", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Stats, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"a-drive-backend/database"
+)
+
+// copyDatabase copies every table in one transaction of the target, so that
+// a failed copy leaves it empty
+func copyDatabase(source, target *gorm.DB, batchSize int) error {
+	return target.Transaction(func(tx *gorm.DB) error {
+		for _, model := range database.Models() {
+			if err := copyTable(source, tx, model, batchSize); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// copyTable copies the rows of a model, soft-deleted ones included, keeping
+// their IDs. Rows are inserted as column maps so that false and zero values
+// are not replaced with column defaults and no hooks run.
+func copyTable(source, target *gorm.DB, model interface{}, batchSize int) error {
+	stmt := &gorm.Statement{DB: target}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+
+	// Databases of older versions may lack newer tables
+	if !source.Migrator().HasTable(model) {
+		log.Printf("%s: not in the source database, skipped", table)
+		return nil
+	}
+	var existing int64
+	if err := target.Table(table).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("table %s of the target already has rows; copy into an empty database", table)
+	}
+
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+	var copied int
+	err := source.Unscoped().Model(model).FindInBatches(rows.Interface(), batchSize, func(batch *gorm.DB, _ int) error {
+		records := make([]map[string]interface{}, rows.Elem().Len())
+		for i := range records {
+			records[i] = columnValues(stmt.Schema, rows.Elem().Index(i))
+		}
+		copied += len(records)
+		return target.Table(table).Create(&records).Error
+	}).Error
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", table, err)
+	}
+
+	if err := resetSequence(target, stmt.Schema); err != nil {
+		return fmt.Errorf("reset the ID sequence of %s: %w", table, err)
+	}
+	log.Printf("%s: %d rows", table, copied)
+	return nil
+}
+
+func columnValues(s *schema.Schema, row reflect.Value) map[string]interface{} {
+	values := make(map[string]interface{}, len(s.DBNames))
+	for _, name := range s.DBNames {
+		values[name], _ = s.FieldsByDBName[name].ValueOf(context.Background(), row)
+	}
+	return values
+}
+
+// resetSequence moves the ID sequence of a PostgreSQL table past the copied
+// IDs. MySQL and SQLite do that on their own when IDs are inserted.
+func resetSequence(db *gorm.DB, s *schema.Schema) error {
+	id := s.PrioritizedPrimaryField
+	if db.Dialector.Name() != database.Postgres || id == nil || !id.AutoIncrement {
+		return nil
+	}
+	return db.Exec(fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+		s.Table, id.DBName, id.DBName, s.Table)).Error
+}
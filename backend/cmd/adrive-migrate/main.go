@@ -0,0 +1,132 @@
+// Command adrive-migrate applies, rolls back and lists the schema migrations
+// of an A-Drive database.
+//
+// Usage:
+//
+//	adrive-migrate [-database URL] status
+//	adrive-migrate [-database URL] up
+//	adrive-migrate [-database URL] down [-steps N]
+//
+// -database defaults to DATABASE_URL, or DATABASE_PATH when that is empty,
+// read from the environment or a .env file like the server does. The server
+// applies pending migrations itself on startup unless DB_AUTO_MIGRATE is
+// false. Stop the server before rolling back, and back up the database: down
+// migrations can drop data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+
+	"a-drive-backend/config"
+	"a-drive-backend/database"
+)
+
+func main() {
+	godotenv.Load()
+	cfg := config.Load()
+
+	databaseURL := flag.String("database", database.URL(cfg.DatabaseURL, cfg.DatabasePath), "database URL or SQLite path")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-database URL] status|up|down [-steps N]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := database.Open(*databaseURL, 0)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "status":
+		parseArgs(command, args)
+		if err := printStatus(db); err != nil {
+			log.Fatalf("Failed to read migrations: %v", err)
+		}
+	case "up":
+		parseArgs(command, args)
+		done, err := database.Migrate(db)
+		for _, m := range done {
+			fmt.Printf("Applied %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+	case "down":
+		fs := flag.NewFlagSet(command, flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args)
+		if *steps < 1 || fs.NArg() > 0 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		done, err := database.Rollback(db, *steps)
+		for _, m := range done {
+			fmt.Printf("Rolled back %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("No migrations to roll back")
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// parseArgs rejects arguments after a command that takes none
+func parseArgs(command string, args []string) {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// printStatus lists the migrations and the current schema version
+func printStatus(db *gorm.DB) error {
+	statuses, err := database.Status(db)
+	if err != nil {
+		return err
+	}
+	version, err := database.SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		if s.Unknown {
+			applied += " (unknown to this binary)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nSchema version %d, this binary migrates to %d\n", version, database.LatestVersion())
+	return nil
+}
@@ -0,0 +1,164 @@
+// Command adrive-sync mirrors a local directory to an A-Drive folder in both
+// directions.
+//
+// Usage:
+//
+//	adrive-sync -server http://localhost:8080 -user alice -local ~/Documents -remote Documents [-watch]
+//
+// The password is read from the ADRIVE_PASSWORD environment variable unless
+// -password is given. Sync state is kept in .adrive-sync.json inside the local
+// directory; deleting it makes the next run a fresh two-way merge that never
+// deletes anything.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"a-drive-backend/client"
+)
+
+func main() {
+	server := flag.String("server", envOr("ADRIVE_SERVER", "http://localhost:8080"), "A-Drive server URL")
+	username := flag.String("user", os.Getenv("ADRIVE_USERNAME"), "username to log in with")
+	password := flag.String("password", os.Getenv("ADRIVE_PASSWORD"), "password (defaults to $ADRIVE_PASSWORD)")
+	localDir := flag.String("local", "", "local directory to synchronize")
+	remoteDir := flag.String("remote", "", "remote folder path, e.g. Projects/Docs (empty for the root)")
+	watch := flag.Bool("watch", false, "keep running and sync whenever either side changes")
+	interval := flag.Duration("interval", 30*time.Second, "polling interval in watch mode")
+	flag.Parse()
+
+	if *localDir == "" || *username == "" || *password == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := os.MkdirAll(*localDir, 0755); err != nil {
+		log.Fatalf("Failed to create local directory: %v", err)
+	}
+
+	api := client.New(*server)
+	if _, err := api.Login(ctx, *username, *password); err != nil {
+		log.Fatalf("Login failed: %v", err)
+	}
+
+	remoteRoot, err := resolveRemoteFolder(ctx, api, *remoteDir)
+	if err != nil {
+		log.Fatalf("Failed to resolve remote folder %q: %v", *remoteDir, err)
+	}
+
+	state, err := loadState(*localDir, api.BaseURL(), remoteRoot)
+	if err != nil {
+		log.Fatalf("Failed to load sync state: %v", err)
+	}
+
+	hostname, _ := os.Hostname()
+	s := &syncer{
+		api:        api,
+		localRoot:  *localDir,
+		remoteRoot: remoteRoot,
+		state:      state,
+		hostname:   hostname,
+	}
+
+	if !*watch {
+		if err := s.run(ctx); err != nil {
+			log.Fatalf("Sync failed: %v", err)
+		}
+		return
+	}
+
+	if err := watchLoop(ctx, s, *interval); err != nil && ctx.Err() == nil {
+		log.Fatalf("Sync failed: %v", err)
+	}
+}
+
+// watchLoop syncs once, then polls the change feed and the local directory
+// and runs another pass whenever either side moved on
+func watchLoop(ctx context.Context, s *syncer, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.run(ctx); err != nil {
+			log.Printf("Sync pass failed: %v", err)
+		}
+		fingerprint, err := s.localFingerprint()
+		if err != nil {
+			return err
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+
+			cursor, err := s.api.LatestCursor(ctx)
+			if err != nil {
+				log.Printf("Failed to poll changes: %v", err)
+				continue
+			}
+			current, err := s.localFingerprint()
+			if err != nil {
+				return err
+			}
+			if cursor != s.state.Cursor || current != fingerprint {
+				break
+			}
+		}
+	}
+}
+
+// resolveRemoteFolder walks a slash-separated folder path from the root,
+// creating folders that do not exist yet
+func resolveRemoteFolder(ctx context.Context, api *client.Client, remotePath string) (*uint, error) {
+	var current *uint
+	for _, name := range strings.Split(path.Clean("/"+remotePath), "/") {
+		if name == "" {
+			continue
+		}
+
+		listing, err := api.ListFiles(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+
+		var next *uint
+		for _, folder := range listing.Folders {
+			if folder.Name == name {
+				id := folder.ID
+				next = &id
+				break
+			}
+		}
+		if next == nil {
+			folder, err := api.CreateFolder(ctx, client.CreateFolderRequest{Name: name, ParentID: current})
+			if err != nil {
+				return nil, fmt.Errorf("create %s: %w", name, err)
+			}
+			next = &folder.ID
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func envOr(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
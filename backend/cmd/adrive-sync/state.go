@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// stateFilePrefix marks files owned by the sync client; they are never synced
+	stateFilePrefix = ".adrive-sync"
+	stateFileName   = stateFilePrefix + ".json"
+)
+
+// syncState is the last state both sides agreed on. It is the common
+// ancestor used to tell local edits from remote edits and deletions from
+// creations.
+type syncState struct {
+	Server         string                 `json:"server"`
+	RemoteFolderID *uint                  `json:"remote_folder_id"`
+	Cursor         uint64                 `json:"cursor"`
+	Entries        map[string]*stateEntry `json:"entries"`
+}
+
+type stateEntry struct {
+	IsDir           bool      `json:"is_dir,omitempty"`
+	RemoteID        uint      `json:"remote_id"`
+	Checksum        string    `json:"checksum,omitempty"`
+	RemoteUpdatedAt time.Time `json:"remote_updated_at,omitempty"`
+	LocalSize       int64     `json:"local_size,omitempty"`
+	LocalModTime    time.Time `json:"local_mod_time,omitempty"`
+}
+
+func newSyncState(server string, remoteFolderID *uint) *syncState {
+	return &syncState{
+		Server:         server,
+		RemoteFolderID: remoteFolderID,
+		Entries:        make(map[string]*stateEntry),
+	}
+}
+
+// loadState reads the state file, starting over when it is missing or was
+// written for a different server or remote folder
+func loadState(localRoot, server string, remoteFolderID *uint) (*syncState, error) {
+	data, err := os.ReadFile(filepath.Join(localRoot, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return newSyncState(server, remoteFolderID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state syncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Server != server || !sameFolder(state.RemoteFolderID, remoteFolderID) || state.Entries == nil {
+		return newSyncState(server, remoteFolderID), nil
+	}
+	return &state, nil
+}
+
+func (s *syncState) save(localRoot string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(localRoot, stateFileName+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(localRoot, stateFileName))
+}
+
+func sameFolder(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"a-drive-backend/client"
+)
+
+type localEntry struct {
+	IsDir    bool
+	Size     int64
+	ModTime  time.Time
+	Checksum string
+}
+
+type remoteEntry struct {
+	IsDir     bool
+	ID        uint
+	Checksum  string
+	UpdatedAt time.Time
+}
+
+// syncer reconciles a local directory with a remote folder. Paths are
+// slash-separated and relative to both roots.
+type syncer struct {
+	api        *client.Client
+	localRoot  string
+	remoteRoot *uint
+	state      *syncState
+	hostname   string
+
+	remote map[string]*remoteEntry
+	next   map[string]*stateEntry
+}
+
+// run performs one full two-way synchronization pass
+func (s *syncer) run(ctx context.Context) error {
+	// Read the cursor before listing so that anything changing during the
+	// pass shows up as new work for watch mode
+	cursor, err := s.api.LatestCursor(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch change cursor: %w", err)
+	}
+
+	local, err := s.scanLocal()
+	if err != nil {
+		return fmt.Errorf("scan local directory: %w", err)
+	}
+	if s.remote, err = s.scanRemote(ctx); err != nil {
+		return fmt.Errorf("list remote folder: %w", err)
+	}
+	s.next = make(map[string]*stateEntry)
+
+	var files, dirs []string
+	seen := make(map[string]bool)
+	add := func(p string, isDir bool) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		if isDir {
+			dirs = append(dirs, p)
+		} else {
+			files = append(files, p)
+		}
+	}
+	for p, l := range local {
+		add(p, l.IsDir)
+	}
+	for p, r := range s.remote {
+		add(p, r.IsDir)
+	}
+	for p, st := range s.state.Entries {
+		add(p, st.IsDir)
+	}
+	sort.Strings(files)
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+
+	for _, p := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.syncFile(ctx, p, local[p], s.remote[p], s.state.Entries[p]); err != nil {
+			log.Printf("%s: %v", p, err)
+			// Keep the previous state so the next pass retries
+			if st := s.state.Entries[p]; st != nil {
+				s.next[p] = st
+			}
+		}
+	}
+
+	// Directories go deepest first so that emptied parents can be removed
+	for _, p := range dirs {
+		if p == "" {
+			continue
+		}
+		if err := s.syncDir(ctx, p, local[p], s.remote[p], s.state.Entries[p]); err != nil {
+			log.Printf("%s/: %v", p, err)
+		}
+	}
+
+	s.state.Entries = s.next
+	s.state.Cursor = cursor
+	return s.state.save(s.localRoot)
+}
+
+func (s *syncer) syncFile(ctx context.Context, p string, l *localEntry, r *remoteEntry, st *stateEntry) error {
+	if (l != nil && l.IsDir) || (r != nil && r.IsDir) {
+		return fmt.Errorf("is a file on one side and a folder on the other, skipping")
+	}
+
+	switch {
+	case l != nil && r != nil:
+		localChanged := st == nil || l.Checksum != st.Checksum
+		remoteChanged := st == nil || !remoteMatchesState(r, st)
+		switch {
+		case r.Checksum != "" && l.Checksum == r.Checksum, !localChanged && !remoteChanged:
+			s.keep(p, l, r)
+			return nil
+		case localChanged && !remoteChanged:
+			log.Printf("upload %s", p)
+			return s.replaceRemote(ctx, p, r)
+		case !localChanged && remoteChanged:
+			log.Printf("download %s", p)
+			return s.download(ctx, p, r)
+		default:
+			return s.resolveConflict(ctx, p, r)
+		}
+
+	case l != nil:
+		if st != nil && l.Checksum == st.Checksum {
+			log.Printf("delete local %s (deleted remotely)", p)
+			return os.Remove(s.localPath(p))
+		}
+		log.Printf("upload %s", p)
+		return s.upload(ctx, p)
+
+	case r != nil:
+		if st != nil && remoteMatchesState(r, st) {
+			log.Printf("delete remote %s (deleted locally)", p)
+			return s.api.DeleteFile(ctx, r.ID)
+		}
+		log.Printf("download %s", p)
+		return s.download(ctx, p, r)
+	}
+
+	// Deleted on both sides
+	return nil
+}
+
+func (s *syncer) syncDir(ctx context.Context, p string, l *localEntry, r *remoteEntry, st *stateEntry) error {
+	if (l != nil && !l.IsDir) || (r != nil && !r.IsDir) {
+		return nil // reported by syncFile
+	}
+
+	switch {
+	case l != nil && r != nil:
+		s.next[p] = &stateEntry{IsDir: true, RemoteID: r.ID}
+		return nil
+
+	case l != nil:
+		if st != nil && !s.hasSurvivors(p) {
+			log.Printf("delete local %s/ (deleted remotely)", p)
+			return os.Remove(s.localPath(p))
+		}
+		id, err := s.ensureRemoteDir(ctx, p)
+		if err != nil {
+			return err
+		}
+		s.next[p] = &stateEntry{IsDir: true, RemoteID: *id}
+		return nil
+
+	case r != nil:
+		if st != nil && !s.hasSurvivors(p) {
+			log.Printf("delete remote %s/ (deleted locally)", p)
+			return s.api.DeleteFolder(ctx, r.ID)
+		}
+		if err := os.MkdirAll(s.localPath(p), 0755); err != nil {
+			return err
+		}
+		s.next[p] = &stateEntry{IsDir: true, RemoteID: r.ID}
+		return nil
+	}
+	return nil
+}
+
+// resolveConflict keeps both edits: the local copy is renamed to a conflict
+// copy that is uploaded next to the remote version, which wins the original name
+func (s *syncer) resolveConflict(ctx context.Context, p string, r *remoteEntry) error {
+	conflict := s.conflictPath(p)
+	log.Printf("conflict %s, keeping local edits as %s", p, conflict)
+
+	if err := os.Rename(s.localPath(p), s.localPath(conflict)); err != nil {
+		return err
+	}
+	if err := s.download(ctx, p, r); err != nil {
+		return err
+	}
+	return s.upload(ctx, conflict)
+}
+
+func (s *syncer) upload(ctx context.Context, p string) error {
+	parentID, err := s.ensureRemoteDir(ctx, path.Dir(p))
+	if err != nil {
+		return err
+	}
+	file, err := uploadFile(ctx, s.api, s.localPath(p), parentID)
+	if err != nil {
+		return err
+	}
+	return s.recordSynced(p, &remoteEntry{ID: file.ID, Checksum: file.Checksum, UpdatedAt: file.UpdatedAt})
+}
+
+func (s *syncer) replaceRemote(ctx context.Context, p string, r *remoteEntry) error {
+	file, err := replaceFile(ctx, s.api, r.ID, s.localPath(p))
+	if err != nil {
+		return err
+	}
+	return s.recordSynced(p, &remoteEntry{ID: file.ID, Checksum: file.Checksum, UpdatedAt: file.UpdatedAt})
+}
+
+func (s *syncer) download(ctx context.Context, p string, r *remoteEntry) error {
+	if err := downloadFile(ctx, s.api, r.ID, s.localPath(p)); err != nil {
+		return err
+	}
+	return s.recordSynced(p, r)
+}
+
+// keep records a file that is already identical on both sides
+func (s *syncer) keep(p string, l *localEntry, r *remoteEntry) {
+	s.next[p] = &stateEntry{
+		RemoteID:        r.ID,
+		Checksum:        l.Checksum,
+		RemoteUpdatedAt: r.UpdatedAt,
+		LocalSize:       l.Size,
+		LocalModTime:    l.ModTime,
+	}
+}
+
+// recordSynced stores the state of a file right after it was transferred
+func (s *syncer) recordSynced(p string, r *remoteEntry) error {
+	info, err := os.Stat(s.localPath(p))
+	if err != nil {
+		return err
+	}
+	checksum, err := fileChecksum(s.localPath(p))
+	if err != nil {
+		return err
+	}
+	s.next[p] = &stateEntry{
+		RemoteID:        r.ID,
+		Checksum:        checksum,
+		RemoteUpdatedAt: r.UpdatedAt,
+		LocalSize:       info.Size(),
+		LocalModTime:    info.ModTime(),
+	}
+	return nil
+}
+
+// ensureRemoteDir returns the remote folder for p, creating missing folders
+func (s *syncer) ensureRemoteDir(ctx context.Context, p string) (*uint, error) {
+	if p == "." || p == "" {
+		return s.remoteRoot, nil
+	}
+	if r, ok := s.remote[p]; ok && r.IsDir {
+		return &r.ID, nil
+	}
+
+	parentID, err := s.ensureRemoteDir(ctx, path.Dir(p))
+	if err != nil {
+		return nil, err
+	}
+	folder, err := s.api.CreateFolder(ctx, client.CreateFolderRequest{Name: path.Base(p), ParentID: parentID})
+	if err != nil {
+		return nil, err
+	}
+	s.remote[p] = &remoteEntry{IsDir: true, ID: folder.ID}
+	return &folder.ID, nil
+}
+
+// hasSurvivors reports whether anything below directory p is kept after this pass
+func (s *syncer) hasSurvivors(p string) bool {
+	prefix := p + "/"
+	for other := range s.next {
+		if strings.HasPrefix(other, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *syncer) conflictPath(p string) string {
+	ext := path.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	return fmt.Sprintf("%s (conflict from %s %s)%s", base, s.hostname, time.Now().Format("2006-01-02 150405"), ext)
+}
+
+func (s *syncer) localPath(p string) string {
+	return filepath.Join(s.localRoot, filepath.FromSlash(p))
+}
+
+// scanLocal walks the local directory, reusing checksums from the state for
+// files whose size and modification time did not change
+func (s *syncer) scanLocal() (map[string]*localEntry, error) {
+	entries := make(map[string]*localEntry)
+	err := filepath.WalkDir(s.localRoot, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if fullPath == s.localRoot {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), stateFilePrefix) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.localRoot, fullPath)
+		if err != nil {
+			return err
+		}
+		p := filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			entries[p] = &localEntry{IsDir: true}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry := &localEntry{Size: info.Size(), ModTime: info.ModTime()}
+		if st := s.state.Entries[p]; st != nil && st.LocalSize == entry.Size && st.LocalModTime.Equal(entry.ModTime) {
+			entry.Checksum = st.Checksum
+		} else if entry.Checksum, err = fileChecksum(fullPath); err != nil {
+			return err
+		}
+		entries[p] = entry
+		return nil
+	})
+	return entries, err
+}
+
+// scanRemote lists the remote folder tree breadth first
+func (s *syncer) scanRemote(ctx context.Context) (map[string]*remoteEntry, error) {
+	entries := make(map[string]*remoteEntry)
+
+	type pending struct {
+		path string
+		id   *uint
+	}
+	queue := []pending{{path: "", id: s.remoteRoot}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		listing, err := s.api.ListFiles(ctx, current.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range listing.Folders {
+			p := path.Join(current.path, folder.Name)
+			id := folder.ID
+			entries[p] = &remoteEntry{IsDir: true, ID: id}
+			queue = append(queue, pending{path: p, id: &id})
+		}
+		for _, file := range listing.Files {
+			p := path.Join(current.path, file.Name)
+			entries[p] = &remoteEntry{ID: file.ID, Checksum: file.Checksum, UpdatedAt: file.UpdatedAt}
+		}
+	}
+	return entries, nil
+}
+
+// localFingerprint summarizes names, sizes and modification times so watch
+// mode can notice local edits without hashing file contents
+func (s *syncer) localFingerprint() (string, error) {
+	hash := md5.New()
+	err := filepath.WalkDir(s.localRoot, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), stateFilePrefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", fullPath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return fmt.Sprintf("%x", hash.Sum(nil)), err
+}
+
+// remoteMatchesState reports whether a remote file is unchanged since the last
+// sync. Files uploaded before checksums were recorded fall back to updated_at.
+func remoteMatchesState(r *remoteEntry, st *stateEntry) bool {
+	if r.Checksum != "" {
+		return r.Checksum == st.Checksum
+	}
+	return r.ID == st.RemoteID && r.UpdatedAt.Equal(st.RemoteUpdatedAt)
+}
+
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"a-drive-backend/client"
+)
+
+// uploadFile creates a new remote file from a local one
+func uploadFile(ctx context.Context, api *client.Client, localPath string, folderID *uint) (*client.File, error) {
+	src, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return api.Upload(ctx, folderID, filepath.Base(localPath), src)
+}
+
+// replaceFile overwrites the content of an existing remote file
+func replaceFile(ctx context.Context, api *client.Client, fileID uint, localPath string) (*client.File, error) {
+	src, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return api.ReplaceContent(ctx, fileID, filepath.Base(localPath), src, "")
+}
+
+// downloadFile streams a remote file into dst atomically
+func downloadFile(ctx context.Context, api *client.Client, fileID uint, dst string) error {
+	body, err := api.Download(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), stateFilePrefix+"download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -4,10 +4,13 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	DatabasePath    string
+	DatabaseURL     string // postgres://, mysql:// or sqlite:// URL; DatabasePath is used when empty
+	AutoMigrate     bool   // apply pending schema migrations on startup
 	JWTSecret      string
 	RootDirectory  string
 	MaxFileSize    int64
@@ -16,13 +19,77 @@ type Config struct {
 	CORSOrigins    string
 	CORSMethods    string
 	CORSHeaders    string
+	TrustedProxies string // reverse proxies whose X-Forwarded-For and X-Real-IP headers are believed
+	OpenAPIValidation bool
+	ArchiveMaxEntries int   // members an archive may have to be extracted
+	ArchiveMaxSize    int64 // total uncompressed bytes of an extraction
+	ArchiveMaxRatio   int64 // uncompressed size divided by archive size
+	VersionPruneInterval time.Duration // how often old versions are pruned, 0 disables
+	DiffMaxSize          int64         // largest version that can be compared, in bytes
+	VersionChunkMinSize  int64         // old versions at least this large go to the chunk store
+	ShareSessionTTL         time.Duration // how long a password share stays unlocked
+	SharePasswordAttempts   int           // failed passwords per share within the window
+	SharePasswordIPAttempts int           // failed passwords per client IP within the window
+	SharePasswordWindow     time.Duration // period failed password attempts are counted over
+	GeoIPDatabase           string        // CSV of address ranges and countries for share analytics
+	ShareExpiryInterval     time.Duration // how often ended shares are marked and owners notified, 0 disables
+	PreviewMaxRenderSize    int64         // largest Markdown or code file rendered as HTML, in bytes
+	MetricsEnabled          bool          // serve Prometheus metrics at /metrics
+	MetricsToken            string        // bearer token required to read /metrics, empty for none
+	LogLevel                string        // debug, info, warn or error
+	LogFormat               string        // json or text
+	SlowQueryThreshold      time.Duration // statements slower than this are logged as warnings, 0 disables
+	ShutdownTimeout         time.Duration // how long in-flight requests may take to finish on shutdown
+	ShutdownDrainDelay      time.Duration // how long /readyz fails before the server stops accepting connections
+	ReadyMinFreeSpace       int64         // bytes the storage file system needs free to be ready, 0 disables
+	MaintenanceMode         bool          // start with maintenance mode on
+	MaintenanceMessage      string        // shown to clients whose changes are rejected in maintenance mode
 }
 
 func Load() *Config {
 	maxSize, _ := strconv.ParseInt(getEnv("MAX_FILE_SIZE", "104857600"), 10, 64)
+	archiveEntries, _ := strconv.Atoi(getEnv("ARCHIVE_MAX_ENTRIES", "10000"))
+	archiveSize, _ := strconv.ParseInt(getEnv("ARCHIVE_MAX_SIZE", "1073741824"), 10, 64)
+	archiveRatio, _ := strconv.ParseInt(getEnv("ARCHIVE_MAX_RATIO", "100"), 10, 64)
+	chunkMinSize, _ := strconv.ParseInt(getEnv("VERSION_CHUNK_MIN_SIZE", "1048576"), 10, 64)
+	diffSize, _ := strconv.ParseInt(getEnv("DIFF_MAX_SIZE", "1048576"), 10, 64)
+	renderSize, _ := strconv.ParseInt(getEnv("PREVIEW_MAX_RENDER_SIZE", "1048576"), 10, 64)
+	pruneInterval, err := time.ParseDuration(getEnv("VERSION_PRUNE_INTERVAL", "1h"))
+	if err != nil {
+		pruneInterval = time.Hour
+	}
+	shareAttempts, _ := strconv.Atoi(getEnv("SHARE_PASSWORD_MAX_ATTEMPTS", "10"))
+	shareIPAttempts, _ := strconv.Atoi(getEnv("SHARE_PASSWORD_MAX_ATTEMPTS_PER_IP", "20"))
+	sessionTTL, err := time.ParseDuration(getEnv("SHARE_SESSION_TTL", "15m"))
+	if err != nil || sessionTTL <= 0 {
+		sessionTTL = 15 * time.Minute
+	}
+	attemptWindow, err := time.ParseDuration(getEnv("SHARE_PASSWORD_WINDOW", "15m"))
+	if err != nil || attemptWindow <= 0 {
+		attemptWindow = 15 * time.Minute
+	}
+	expiryInterval, err := time.ParseDuration(getEnv("SHARE_EXPIRY_INTERVAL", "5m"))
+	if err != nil {
+		expiryInterval = 5 * time.Minute
+	}
+	slowQuery, err := time.ParseDuration(getEnv("DB_SLOW_QUERY_THRESHOLD", "200ms"))
+	if err != nil {
+		slowQuery = 200 * time.Millisecond
+	}
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "60s"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = time.Minute
+	}
+	drainDelay, err := time.ParseDuration(getEnv("SHUTDOWN_DRAIN_DELAY", "0s"))
+	if err != nil || drainDelay < 0 {
+		drainDelay = 0
+	}
+	minFreeSpace, _ := strconv.ParseInt(getEnv("READY_MIN_FREE_SPACE", "104857600"), 10, 64)
 	
 	return &Config{
 		DatabasePath:   getEnv("DATABASE_PATH", "./storage/database.db"),
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+		AutoMigrate:    getEnv("DB_AUTO_MIGRATE", "true") == "true",
 		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
 		RootDirectory: getEnv("ROOT_DIRECTORY", "./storage/files"),
 		MaxFileSize:   maxSize,
@@ -31,6 +98,31 @@ func Load() *Config {
 		CORSOrigins:   getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:3001"),
 		CORSMethods:   getEnv("CORS_METHODS", "GET,POST,PUT,DELETE,OPTIONS"),
 		CORSHeaders:   getEnv("CORS_HEADERS", "Origin,Content-Type,Authorization"),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+		OpenAPIValidation: getEnv("OPENAPI_VALIDATION", "false") == "true",
+		ArchiveMaxEntries: archiveEntries,
+		ArchiveMaxSize:    archiveSize,
+		ArchiveMaxRatio:   archiveRatio,
+		VersionPruneInterval: pruneInterval,
+		DiffMaxSize:          diffSize,
+		VersionChunkMinSize:  chunkMinSize,
+		ShareSessionTTL:         sessionTTL,
+		SharePasswordAttempts:   shareAttempts,
+		SharePasswordIPAttempts: shareIPAttempts,
+		SharePasswordWindow:     attemptWindow,
+		GeoIPDatabase:           getEnv("GEOIP_DATABASE", ""),
+		ShareExpiryInterval:     expiryInterval,
+		PreviewMaxRenderSize:    renderSize,
+		MetricsEnabled:          getEnv("METRICS_ENABLED", "true") == "true",
+		MetricsToken:            getEnv("METRICS_TOKEN", ""),
+		LogLevel:                getEnv("LOG_LEVEL", "info"),
+		LogFormat:               getEnv("LOG_FORMAT", "json"),
+		SlowQueryThreshold:      slowQuery,
+		ShutdownTimeout:         shutdownTimeout,
+		ShutdownDrainDelay:      drainDelay,
+		ReadyMinFreeSpace:       minFreeSpace,
+		MaintenanceMode:         getEnv("MAINTENANCE_MODE", "false") == "true",
+		MaintenanceMessage:      getEnv("MAINTENANCE_MESSAGE", ""),
 	}
 }
 
@@ -58,6 +150,12 @@ func (c *Config) GetCORSOrigins() []string {
 	return c.ParseCSV(c.CORSOrigins)
 }
 
+// GetTrustedProxies returns the trusted proxy addresses and CIDR ranges as a
+// slice; empty trusts none, so client addresses are taken from connections
+func (c *Config) GetTrustedProxies() []string {
+	return c.ParseCSV(c.TrustedProxies)
+}
+
 // GetCORSMethods returns the CORS methods as a slice  
 func (c *Config) GetCORSMethods() []string {
 	return c.ParseCSV(c.CORSMethods)
@@ -2,54 +2,86 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/config"
+	"a-drive-backend/logging"
 	"a-drive-backend/models"
 	"a-drive-backend/utils"
 )
 
-func Init(databasePath string) *gorm.DB {
-	dir := filepath.Dir(databasePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Fatal("Failed to create database directory:", err)
+func Init(cfg *config.Config) *gorm.DB {
+	db, err := Open(URL(cfg.DatabaseURL, cfg.DatabasePath), cfg.SlowQueryThreshold)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
+	slog.Info("Connected to database", "driver", db.Dialector.Name())
 
-	db, err := gorm.Open(sqlite.Open(databasePath), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	if err := instrument(db); err != nil {
+		logging.Fatal("Failed to register database metrics", "error", err)
+	}
+
+	if err := CheckSchemaVersion(db); err != nil {
+		logging.Fatal("Refusing to start", "error", err)
+	}
+	if cfg.AutoMigrate {
+		if _, err := Migrate(db); err != nil {
+			logging.Fatal("Failed to migrate database", "error", err)
+		}
+	} else if pending, err := Pending(db); err != nil {
+		logging.Fatal("Failed to read schema migrations", "error", err)
+	} else if len(pending) > 0 {
+		logging.Fatal("Database has pending migrations; apply them with adrive-migrate up", "pending", len(pending))
 	}
 
-	if err := migrate(db); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	if err := seedRoles(db); err != nil {
+		logging.Fatal("Failed to create built-in roles", "error", err)
 	}
 
 	if err := createAdminUser(db); err != nil {
-		log.Println("Admin user creation skipped:", err)
+		slog.Info("Admin user creation skipped", "reason", err)
 	}
 
 	return db
 }
 
-func migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+// Models lists every table, parents before the tables that refer to them
+func Models() []interface{} {
+	return []interface{}{
+		&models.Role{},
 		&models.User{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.TeamDrive{},
+		&models.TeamDriveMember{},
 		&models.Folder{},
 		&models.File{},
 		&models.FileVersion{},
+		&models.Chunk{},
+		&models.VersionChunk{},
 		&models.FileShare{},
 		&models.ShareAccess{},
 		&models.Favorite{},
 		&models.RecentAccess{},
-	)
+		&models.Change{},
+		&models.VersioningPolicy{},
+		&models.Notification{},
+	}
+}
+
+// seedRoles creates the built-in roles that do not exist yet
+func seedRoles(db *gorm.DB) error {
+	for _, role := range authz.BuiltInRoles() {
+		if err := db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func createAdminUser(db *gorm.DB) error {
@@ -69,7 +101,7 @@ func createAdminUser(db *gorm.DB) error {
 		Username:     "admin",
 		Email:        "admin@example.com",
 		PasswordHash: hashedPassword,
-		Role:         "admin",
+		Role:         authz.RoleAdmin,
 	}
 
 	if err := db.Create(&adminUser).Error; err != nil {
@@ -78,9 +110,9 @@ func createAdminUser(db *gorm.DB) error {
 
 	userDir := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", adminUser.ID))
 	if err := os.MkdirAll(userDir, 0755); err != nil {
-		log.Printf("Failed to create admin user directory: %v", err)
+		slog.Error("Failed to create admin user directory", "error", err)
 	}
 
-	log.Println("Created admin user - Username: admin, Password: admin123")
+	slog.Warn("Created admin user with the default password; change it", "username", "admin")
 	return nil
 }
\ No newline at end of file
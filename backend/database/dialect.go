@@ -0,0 +1,35 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes LIKE wildcards with "!" rather than a backslash, which
+// MySQL would also read as an escape in the string literal
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// EscapeLike makes s match literally inside a pattern for ILike
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ILike is a case-insensitive LIKE condition on column whose placeholder
+// takes a pattern escaped with EscapeLike. SQLite's LIKE ignores case but
+// PostgreSQL's does not.
+func ILike(column string) string {
+	return "LOWER(" + column + ") LIKE LOWER(?) ESCAPE '!'"
+}
+
+// DateExpr is an expression for the UTC date of a timestamp column as
+// YYYY-MM-DD text, which DATE() only returns on SQLite
+func DateExpr(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case Postgres:
+		return "TO_CHAR(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	case MySQL:
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')"
+	}
+	return "DATE(" + column + ")"
+}
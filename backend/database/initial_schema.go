@@ -0,0 +1,280 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The tables of migration 1, as the models defined them when versioned
+// migrations were introduced. These copies are frozen: change the schema in
+// a new migration, never here. Relations are left out, they add no columns.
+
+type initialRole struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"size:255;unique;not null"`
+	Description string
+	Permissions string `gorm:"type:text"`
+	BuiltIn     bool   `gorm:"default:false"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (initialRole) TableName() string { return "roles" }
+
+type initialUser struct {
+	ID           uint   `gorm:"primaryKey"`
+	Username     string `gorm:"size:255;unique;not null"`
+	Email        string `gorm:"size:255;unique;not null"`
+	PasswordHash string `gorm:"not null"`
+	Role         string `gorm:"default:user"`
+	Disabled     bool   `gorm:"default:false"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialUser) TableName() string { return "users" }
+
+type initialGroup struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"size:255;unique;not null"`
+	Description string
+	CreatedBy   uint `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (initialGroup) TableName() string { return "groups" }
+
+type initialGroupMember struct {
+	ID        uint `gorm:"primaryKey"`
+	GroupID   uint `gorm:"not null;uniqueIndex:idx_group_member"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_group_member"`
+	IsAdmin   bool `gorm:"default:false"`
+	CreatedAt time.Time
+}
+
+func (initialGroupMember) TableName() string { return "group_members" }
+
+type initialTeamDrive struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null"`
+	GroupID     uint   `gorm:"not null;index"`
+	QuotaBytes  int64  `gorm:"default:0"`
+	DefaultRole string `gorm:"default:contributor;check:default_role IN ('reader','contributor','manager')"`
+	CreatedBy   uint   `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialTeamDrive) TableName() string { return "team_drives" }
+
+type initialTeamDriveMember struct {
+	ID        uint   `gorm:"primaryKey"`
+	DriveID   uint   `gorm:"not null;uniqueIndex:idx_drive_member"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_drive_member"`
+	Role      string `gorm:"not null;check:role IN ('reader','contributor','manager')"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (initialTeamDriveMember) TableName() string { return "team_drive_members" }
+
+type initialFolder struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	ParentID  *uint
+	DriveID   *uint  `gorm:"index"`
+	UserID    uint   `gorm:"not null"`
+	IconType  string `gorm:"default:folder"`
+	IconColor string `gorm:"default:text-blue-500"`
+	Path      string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialFolder) TableName() string { return "folders" }
+
+type initialFile struct {
+	ID                uint   `gorm:"primaryKey"`
+	Name              string `gorm:"not null"`
+	OriginalName      string `gorm:"not null"`
+	FolderID          *uint
+	DriveID           *uint  `gorm:"index"`
+	UserID            uint   `gorm:"not null"`
+	FilePath          string `gorm:"not null"`
+	Size              int64  `gorm:"not null"`
+	MimeType          string
+	Checksum          string
+	CurrentVersion    int  `gorm:"default:1"`
+	VersioningEnabled bool `gorm:"default:false"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialFile) TableName() string { return "files" }
+
+type initialFileVersion struct {
+	ID        uint   `gorm:"primaryKey"`
+	FileID    uint   `gorm:"not null"`
+	Version   int    `gorm:"not null"`
+	FilePath  string `gorm:"not null"`
+	Size      int64  `gorm:"not null"`
+	Checksum  string
+	Chunked   bool `gorm:"default:false"`
+	Comment   string
+	Label     string
+	Pinned    bool `gorm:"default:false"`
+	CreatedBy uint `gorm:"not null"`
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialFileVersion) TableName() string { return "file_versions" }
+
+type initialChunk struct {
+	Hash      string `gorm:"size:64;primaryKey"`
+	Size      int64  `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (initialChunk) TableName() string { return "chunks" }
+
+type initialVersionChunk struct {
+	ID        uint   `gorm:"primaryKey"`
+	VersionID uint   `gorm:"not null;uniqueIndex:idx_version_chunk_seq"`
+	Seq       int    `gorm:"not null;uniqueIndex:idx_version_chunk_seq"`
+	ChunkHash string `gorm:"size:64;not null;index"`
+}
+
+func (initialVersionChunk) TableName() string { return "version_chunks" }
+
+type initialFileShare struct {
+	ID                    uint    `gorm:"primaryKey"`
+	FileID                uint    `gorm:"not null"`
+	SharedBy              uint    `gorm:"not null"`
+	ShareToken            string  `gorm:"size:64;uniqueIndex;not null"`
+	Slug                  *string `gorm:"size:64;uniqueIndex"`
+	ShareType             string  `gorm:"not null"`
+	Password              string  `gorm:"column:password"`
+	ExpiresAt             *time.Time
+	DownloadCount         int `gorm:"default:0"`
+	MaxDownloads          *int
+	AllowPreview          bool `gorm:"default:true"`
+	NotifyOnFirstDownload bool
+	FirstDownloadedAt     *time.Time
+	Disabled              bool
+	Status                string `gorm:"size:20;default:active;index"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+func (initialFileShare) TableName() string { return "file_shares" }
+
+type initialShareAccess struct {
+	ID         uint `gorm:"primaryKey"`
+	ShareID    uint `gorm:"not null;index"`
+	AccessedBy string
+	AccessedAt time.Time `gorm:"index"`
+	UserAgent  string
+	Action     string
+	Referrer   string
+	Country    string
+	CreatedAt  time.Time
+}
+
+func (initialShareAccess) TableName() string { return "share_accesses" }
+
+type initialFavorite struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null"`
+	ItemType  string `gorm:"size:20;not null;check:item_type IN ('file','folder')"`
+	ItemID    uint   `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (initialFavorite) TableName() string { return "favorites" }
+
+type initialRecentAccess struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null"`
+	ItemType   string `gorm:"not null;check:item_type IN ('file','folder')"`
+	ItemID     uint   `gorm:"not null"`
+	AccessedAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (initialRecentAccess) TableName() string { return "recent_accesses" }
+
+type initialChange struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	DriveID   *uint  `gorm:"index"`
+	ItemType  string `gorm:"not null;check:item_type IN ('file','folder')"`
+	ItemID    uint   `gorm:"not null"`
+	Action    string `gorm:"not null"`
+	Name      string
+	ParentID  *uint
+	CreatedAt time.Time
+}
+
+func (initialChange) TableName() string { return "changes" }
+
+type initialVersioningPolicy struct {
+	ID              uint  `gorm:"primaryKey"`
+	FolderID        *uint `gorm:"uniqueIndex"`
+	UserID          *uint `gorm:"uniqueIndex"`
+	Enabled         bool
+	KeepLast        int
+	KeepDailyDays   int
+	KeepWeeklyWeeks int
+	MaxTotalBytes   int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (initialVersioningPolicy) TableName() string { return "versioning_policies" }
+
+type initialNotification struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Type      string `gorm:"not null"`
+	Message   string
+	FileID    *uint
+	ShareID   *uint
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (initialNotification) TableName() string { return "notifications" }
+
+// initialSchema lists the tables of migration 1, parents first
+func initialSchema() []interface{} {
+	return []interface{}{
+		&initialRole{},
+		&initialUser{},
+		&initialGroup{},
+		&initialGroupMember{},
+		&initialTeamDrive{},
+		&initialTeamDriveMember{},
+		&initialFolder{},
+		&initialFile{},
+		&initialFileVersion{},
+		&initialChunk{},
+		&initialVersionChunk{},
+		&initialFileShare{},
+		&initialShareAccess{},
+		&initialFavorite{},
+		&initialRecentAccess{},
+		&initialChange{},
+		&initialVersioningPolicy{},
+		&initialNotification{},
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"a-drive-backend/logging"
+)
+
+// gormLogger writes GORM's logs with the logger of the statement's context,
+// so that they carry the request and user IDs. Failed statements are
+// errors, statements slower than the threshold warnings, and every other
+// statement is only logged at debug level.
+type gormLogger struct {
+	slowThreshold time.Duration
+}
+
+func newGormLogger(slowThreshold time.Duration) logger.Interface {
+	return &gormLogger{slowThreshold: slowThreshold}
+}
+
+// LogMode is part of logger.Interface; the level comes from slog instead
+func (l *gormLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	logging.FromContext(ctx).Info(fmt.Sprintf(msg, data...))
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	logging.FromContext(ctx).Warn(fmt.Sprintf(msg, data...))
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	logging.FromContext(ctx).Error(fmt.Sprintf(msg, data...))
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	log := logging.FromContext(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Error("Database statement failed", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds(), "error", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		sql, rows := fc()
+		log.Warn("Slow database statement", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	case log.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		log.Debug("Database statement", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	}
+}
+
+// ParamsFilter keeps password hashes out of the logged SQL
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	filtered := make([]interface{}, len(params))
+	for i, param := range params {
+		if s, ok := param.(string); ok && isPasswordHash(s) {
+			param = logging.Redacted
+		}
+		filtered[i] = param
+	}
+	return sql, filtered
+}
+
+// isPasswordHash recognizes the bcrypt hashes of account and share passwords
+func isPasswordHash(s string) bool {
+	return len(s) == 60 && (strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$"))
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"a-drive-backend/metrics"
+	"a-drive-backend/models"
+)
+
+const queryStartKey = "metrics:start"
+
+// instrument times every statement with GORM callbacks and reports the
+// storage used by each kind of content
+func instrument(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, op := range []struct {
+		name          string
+		before, after func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	} {
+		operation := op.name
+		if err := op.before("metrics:before_"+operation, startTimer); err != nil {
+			return err
+		}
+		if err := op.after("metrics:after_"+operation, func(tx *gorm.DB) { observe(tx, operation) }); err != nil {
+			return err
+		}
+	}
+
+	metrics.NewGaugeFunc("adrive_storage_used_bytes",
+		"Bytes stored by backend: personal files, team drive files, whole version copies and version chunks.", "backend",
+		func() (map[string]float64, error) { return storageUsed(db) })
+	return nil
+}
+
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(queryStartKey, time.Now())
+}
+
+func observe(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(queryStartKey)
+	if !ok {
+		return
+	}
+	metrics.DBQueryDuration.Observe(time.Since(value.(time.Time)).Seconds(), operation)
+	if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+		metrics.DBErrors.Inc(operation)
+	}
+}
+
+// storageUsed sums the sizes of what is stored in each place
+func storageUsed(db *gorm.DB) (map[string]float64, error) {
+	used := map[string]float64{}
+	for backend, query := range map[string]*gorm.DB{
+		"personal": db.Model(&models.File{}).Where("drive_id IS NULL"),
+		"drives":   db.Model(&models.File{}).Where("drive_id IS NOT NULL"),
+		"versions": db.Model(&models.FileVersion{}).Where("chunked = ?", false),
+		"chunks":   db.Model(&models.Chunk{}),
+	} {
+		var total int64
+		if err := query.Select("COALESCE(SUM(size), 0)").Scan(&total).Error; err != nil {
+			return nil, err
+		}
+		used[backend] = float64(total)
+	}
+	return used, nil
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrSchemaTooNew is returned when the database has migrations applied that
+// this binary does not know, because a newer version of A-Drive ran on it
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of A-Drive")
+
+// ErrIrreversible is returned when rolling back a migration without a Down
+var ErrIrreversible = errors.New("migration cannot be rolled back")
+
+// Migration is one versioned change to the schema or its data. Up and Down
+// run in a transaction together with recording the change in
+// schema_migrations; MySQL commits schema statements immediately, so there a
+// failed migration may have to be cleaned up by hand. A nil Down makes the
+// migration irreversible.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus is a known or applied migration. AppliedAt is nil for
+// pending ones; Unknown marks applied migrations this binary does not have.
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+// LatestVersion is the schema version this binary migrates to
+func LatestVersion() uint {
+	return migrations[len(migrations)-1].Version
+}
+
+// applied returns the applied migrations by version, none when the
+// schema_migrations table does not exist yet
+func applied(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	out := make(map[uint]SchemaMigration)
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return out, nil
+	}
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.Version] = row
+	}
+	return out, nil
+}
+
+// SchemaVersion returns the highest applied migration, 0 for a database
+// that has none
+func SchemaVersion(db *gorm.DB) (uint, error) {
+	done, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+	var version uint
+	for v := range done {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// CheckSchemaVersion fails with ErrSchemaTooNew when the database is ahead of
+// this binary
+func CheckSchemaVersion(db *gorm.DB) error {
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > LatestVersion() {
+		return fmt.Errorf("%w: it is at version %d, this binary knows up to %d; upgrade A-Drive or roll the schema back with the newer adrive-migrate",
+			ErrSchemaTooNew, version, LatestVersion())
+	}
+	return nil
+}
+
+// Status lists every known migration and any unknown applied one, by version
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var out []MigrationStatus
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := done[m.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(done, m.Version)
+		}
+		out = append(out, status)
+	}
+	for _, row := range done {
+		out = append(out, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt, Unknown: true})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Pending returns the migrations that have not been applied, in order
+func Pending(db *gorm.DB) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, m := range migrations {
+		if _, ok := done[m.Version]; !ok {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// Migrate applies every pending migration in order and returns them. It
+// refuses to touch a database whose schema is newer than this binary, and
+// holds the migration lock so that processes starting together apply each
+// migration once.
+func Migrate(db *gorm.DB) ([]Migration, error) {
+	var done []Migration
+	var err error
+	lockErr := withMigrationLock(db, func(db *gorm.DB) {
+		done, err = migrate(db)
+	})
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	return done, err
+}
+
+func migrate(db *gorm.DB) ([]Migration, error) {
+	if err := CheckSchemaVersion(db); err != nil {
+		return nil, err
+	}
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+	}
+	return pending, nil
+}
+
+// Rollback reverts the last steps applied migrations, newest first, and
+// returns them. It stops at the first irreversible one.
+func Rollback(db *gorm.DB, steps int) ([]Migration, error) {
+	var reverted []Migration
+	var err error
+	lockErr := withMigrationLock(db, func(db *gorm.DB) {
+		reverted, err = rollback(db, steps)
+	})
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	return reverted, err
+}
+
+func rollback(db *gorm.DB, steps int) ([]Migration, error) {
+	if err := CheckSchemaVersion(db); err != nil {
+		return nil, err
+	}
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return reverted, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, ErrIrreversible)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		slog.Info("Rolled back migration", "version", m.Version, "name", m.Name)
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// migrationLockID keys the PostgreSQL advisory lock, which is per database
+const migrationLockID = 7349201156
+
+// withMigrationLock creates schema_migrations and runs fn while no other
+// process can change migrations. PostgreSQL and MySQL take a lock on one
+// connection, waiting for as long as another process holds it, and fn gets
+// that connection. SQLite has no such locks: fn runs in a transaction that
+// holds the database write lock, and the migrations nest inside it. Waiting
+// for that lock is limited by the busy timeout of the driver.
+func withMigrationLock(db *gorm.DB, fn func(db *gorm.DB)) error {
+	switch db.Dialector.Name() {
+	case Postgres:
+		return db.Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("lock migrations: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+			if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+				return err
+			}
+			fn(conn)
+			return nil
+		})
+	case MySQL:
+		return db.Connection(func(conn *gorm.DB) error {
+			// Lock names are global to the server, so include the database
+			var locked sql.NullInt64
+			if err := conn.Raw("SELECT GET_LOCK(CONCAT('adrive_migrations.', DATABASE()), -1)").Scan(&locked).Error; err != nil {
+				return fmt.Errorf("lock migrations: %w", err)
+			}
+			if locked.Int64 != 1 {
+				return errors.New("lock migrations: GET_LOCK failed")
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(CONCAT('adrive_migrations.', DATABASE()))")
+			if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+				return err
+			}
+			fn(conn)
+			return nil
+		})
+	}
+
+	// Another process may create the table at the same time, which is not an
+	// error worth logging
+	quiet := db.Session(&gorm.Session{Logger: logger.Discard})
+	if err := quiet.AutoMigrate(&SchemaMigration{}); err != nil && !db.Migrator().HasTable(&SchemaMigration{}) {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Any write takes the lock, even one that changes no rows
+		if err := tx.Exec("UPDATE schema_migrations SET version = version WHERE 1 = 0").Error; err != nil {
+			return fmt.Errorf("lock migrations: %w", err)
+		}
+		// Commit the migrations applied before a failure
+		fn(tx)
+		return nil
+	})
+}
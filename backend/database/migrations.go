@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrations are applied in order of version. Append new ones at the end and
+// never change one that has been released; a change to a model needs a
+// migration of its own.
+//
+// The first creates the tables from the frozen copies in initial_schema.go,
+// both for new databases and for those created by releases before versioned
+// migrations, which it brings up to that schema.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return autoMigrate(tx, initialSchema()...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "favorites_unique_item",
+		Up: func(tx *gorm.DB) error {
+			// Keep the oldest of duplicate favorites. The derived table lets
+			// MySQL delete from the table it selects from.
+			err := tx.Exec(`DELETE FROM favorites WHERE id NOT IN (
+				SELECT id FROM (
+					SELECT MIN(id) AS id FROM favorites GROUP BY user_id, item_type, item_id
+				) AS keep
+			)`).Error
+			if err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX " + favoritesItemIndex + " ON favorites (user_id, item_type, item_id)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropIndex("favorites", favoritesItemIndex)
+		},
+	},
+	{
+		Version: 3,
+		Name:    "folder_shares",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(&folderShare{}, "FileID"); err != nil {
+				return err
+			}
+			// Also restores the indexes SQLite drops when it rebuilds the
+			// table to change the column
+			return autoMigrate(tx, &folderShare{})
+		},
+		Down: func(tx *gorm.DB) error {
+			folderShareIDs := "SELECT id FROM file_shares WHERE folder_id IS NOT NULL"
+			steps := []string{
+				"DELETE FROM share_accesses WHERE share_id IN (" + folderShareIDs + ")",
+				"DELETE FROM notifications WHERE share_id IN (" + folderShareIDs + ")",
+				"DELETE FROM file_shares WHERE folder_id IS NOT NULL",
+			}
+			for _, step := range steps {
+				if err := tx.Exec(step).Error; err != nil {
+					return err
+				}
+			}
+			m := tx.Migrator()
+			if err := m.DropIndex(&folderShare{}, "FolderID"); err != nil {
+				return err
+			}
+			if err := m.DropColumn(&folderShare{}, "FolderID"); err != nil {
+				return err
+			}
+			if err := m.AlterColumn(&initialFileShare{}, "FileID"); err != nil {
+				return err
+			}
+			return autoMigrate(tx, &initialFileShare{})
+		},
+	},
+}
+
+const favoritesItemIndex = "idx_favorites_user_item"
+
+// autoMigrate creates or updates the tables of the given structs
+func autoMigrate(tx *gorm.DB, values ...interface{}) error {
+	if tx.Dialector.Name() == MySQL {
+		// Compare names case-sensitively, as SQLite and PostgreSQL do
+		tx = tx.Set("gorm:table_options", "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin")
+	}
+	return tx.AutoMigrate(values...)
+}
+
+// folderShare is file_shares as of migration 3, which shares folders as well
+// as files: exactly one of FileID and FolderID is set. Frozen like the copies
+// in initial_schema.go.
+type folderShare struct {
+	ID                    uint `gorm:"primaryKey"`
+	FileID                *uint
+	FolderID              *uint   `gorm:"index"`
+	SharedBy              uint    `gorm:"not null"`
+	ShareToken            string  `gorm:"size:64;uniqueIndex;not null"`
+	Slug                  *string `gorm:"size:64;uniqueIndex"`
+	ShareType             string  `gorm:"not null"`
+	Password              string  `gorm:"column:password"`
+	ExpiresAt             *time.Time
+	DownloadCount         int `gorm:"default:0"`
+	MaxDownloads          *int
+	AllowPreview          bool `gorm:"default:true"`
+	NotifyOnFirstDownload bool
+	FirstDownloadedAt     *time.Time
+	Disabled              bool
+	Status                string `gorm:"size:20;default:active;index"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+func (folderShare) TableName() string { return "file_shares" }
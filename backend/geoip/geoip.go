@@ -0,0 +1,137 @@
+// Package geoip looks up the country of an IP address in a local database.
+//
+// The database is a CSV file of address ranges with one range per line:
+// start address, end address and ISO 3166 country code, as in the DB-IP
+// "IP to Country Lite" and IP2Location LITE DB1 downloads. Addresses may be
+// written as IPv4 or IPv6 addresses or as decimal numbers; further columns
+// and lines that do not parse, such as headers, are ignored.
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ipRange struct {
+	start   [16]byte
+	end     [16]byte
+	country string
+}
+
+// DB holds the ranges of a database, sorted by start address
+type DB struct {
+	ranges []ipRange
+}
+
+// Open reads a database file
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses a database
+func Read(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &DB{}
+	countries := map[string]string{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) < 3 {
+			continue
+		}
+		start, ok := parseAddr(record[0])
+		if !ok {
+			continue
+		}
+		end, ok := parseAddr(record[1])
+		if !ok || bytes.Compare(start[:], end[:]) > 0 {
+			continue
+		}
+		code := strings.ToUpper(strings.TrimSpace(record[2]))
+		if len(code) != 2 || code == "ZZ" {
+			continue
+		}
+		// Share the strings, there are only a few hundred countries
+		if shared, ok := countries[code]; ok {
+			code = shared
+		} else {
+			countries[code] = code
+		}
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, country: code})
+	}
+	if len(db.ranges) == 0 {
+		return nil, errors.New("no address ranges found")
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].start[:], db.ranges[j].start[:]) < 0
+	})
+	return db, nil
+}
+
+// Len returns the number of ranges
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
+
+// Country returns the country code of an address, or "" when the address is
+// not valid or not in the database
+func (db *DB) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	key := addr.As16() // IPv4 addresses in their IPv4-mapped form, as stored
+
+	// The last range starting at or before the address
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return bytes.Compare(db.ranges[i].start[:], key[:]) > 0
+	}) - 1
+	if i < 0 || bytes.Compare(db.ranges[i].end[:], key[:]) < 0 {
+		return ""
+	}
+	return db.ranges[i].country
+}
+
+// parseAddr reads an address or a decimal number. IPv4 addresses and numbers
+// below 2^32 are stored in their IPv4-mapped IPv6 form.
+func parseAddr(s string) ([16]byte, bool) {
+	s = strings.TrimSpace(s)
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.As16(), true
+	}
+
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 128 {
+		return [16]byte{}, false
+	}
+	var out [16]byte
+	if n.BitLen() <= 32 {
+		out[10], out[11] = 0xff, 0xff
+		n.FillBytes(out[12:])
+		return out, true
+	}
+	n.FillBytes(out[:])
+	return out, true
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+var errQuotaExceeded = errors.New("team drive quota exceeded")
+
+// storageRoot is the directory holding the files of a user's personal space
+// or of a team drive
+func storageRoot(userID uint, driveID *uint) string {
+	if driveID != nil {
+		return filepath.Join(os.Getenv("ROOT_DIRECTORY"), "drives", fmt.Sprintf("%d", *driveID))
+	}
+	return filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID))
+}
+
+// folderStoragePath is the physical directory of a folder
+func folderStoragePath(folder *models.Folder) string {
+	return filepath.Join(storageRoot(folder.UserID, folder.DriveID), folder.Path)
+}
+
+// findFile loads a file the user may exercise the permission on
+func findFile(db *gorm.DB, fileID interface{}, userID uint, permission authz.Permission) (models.File, error) {
+	var file models.File
+	if err := db.Where("id = ?", fileID).First(&file).Error; err != nil {
+		return file, authz.ErrNotFound
+	}
+	return file, authz.CheckItem(db, userID, file.UserID, file.DriveID, permission)
+}
+
+// findFolder loads a folder the user may exercise the permission on
+func findFolder(db *gorm.DB, folderID interface{}, userID uint, permission authz.Permission) (models.Folder, error) {
+	var folder models.Folder
+	if err := db.Where("id = ?", folderID).First(&folder).Error; err != nil {
+		return folder, authz.ErrNotFound
+	}
+	return folder, authz.CheckItem(db, userID, folder.UserID, folder.DriveID, permission)
+}
+
+// loadFile is findFile for handlers: it writes the error response itself
+func loadFile(c *gin.Context, db *gorm.DB, fileID interface{}, permission authz.Permission) (models.File, bool) {
+	file, err := findFile(db, fileID, c.MustGet("user_id").(uint), permission)
+	if err != nil {
+		respondAccessError(c, err, "File not found")
+		return file, false
+	}
+	return file, true
+}
+
+// loadFolder is findFolder for handlers: it writes the error response itself
+func loadFolder(c *gin.Context, db *gorm.DB, folderID interface{}, permission authz.Permission) (models.Folder, bool) {
+	folder, err := findFolder(db, folderID, c.MustGet("user_id").(uint), permission)
+	if err != nil {
+		respondAccessError(c, err, "Folder not found")
+		return folder, false
+	}
+	return folder, true
+}
+
+// authorizeSpace checks a permission on the personal space, or on a team
+// drive when driveID is set, writing the error response itself
+func authorizeSpace(c *gin.Context, db *gorm.DB, driveID *uint, permission authz.Permission) bool {
+	if err := authz.CheckSpace(db, c.MustGet("user_id").(uint), driveID, permission); err != nil {
+		respondAccessError(c, err, "Team drive not found")
+		return false
+	}
+	return true
+}
+
+// respondAccessError writes the response for a failed authorization check
+func respondAccessError(c *gin.Context, err error, notFound string) {
+	if errors.Is(err, authz.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+}
+
+// personalOrDrive restricts a file or folder query to the user's personal
+// space, or to a team drive when driveID is set
+func personalOrDrive(query *gorm.DB, userID uint, driveID *uint) *gorm.DB {
+	if driveID != nil {
+		return query.Where("drive_id = ?", *driveID)
+	}
+	return query.Where("user_id = ? AND drive_id IS NULL", userID)
+}
+
+// readableItems restricts a file or folder query to everything the user can
+// read: their personal items and the contents of their team drives
+func readableItems(db *gorm.DB, query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where(
+		db.Where("user_id = ? AND drive_id IS NULL", userID).
+			Or("drive_id IN (?)", authz.ReadableDriveIDs(db, userID)),
+	)
+}
+
+// parseDriveID reads an optional team drive ID parameter
+func parseDriveID(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return nil, errors.New("invalid drive ID")
+	}
+	driveID := uint(id)
+	return &driveID, nil
+}
+
+// checkDriveQuota fails when adding extra bytes would exceed a team drive's quota
+func checkDriveQuota(db *gorm.DB, driveID *uint, extra int64) error {
+	if driveID == nil {
+		return nil
+	}
+	var drive models.TeamDrive
+	if err := db.First(&drive, *driveID).Error; err != nil {
+		return err
+	}
+	if drive.QuotaBytes <= 0 {
+		return nil
+	}
+	if driveUsage(db, drive.ID)+extra > drive.QuotaBytes {
+		return errQuotaExceeded
+	}
+	return nil
+}
+
+// respondQuotaError writes the response for a failed checkDriveQuota
+func respondQuotaError(c *gin.Context, err error) {
+	if errors.Is(err, errQuotaExceeded) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Team drive quota exceeded"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check drive quota"})
+}
+
+// driveUsage is the total size of the current content of a drive's files
+func driveUsage(db *gorm.DB, driveID uint) int64 {
+	var used int64
+	db.Model(&models.File{}).Where("drive_id = ?", driveID).Select("COALESCE(SUM(size), 0)").Scan(&used)
+	return used
+}
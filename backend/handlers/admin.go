@@ -6,10 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/logging"
 	"a-drive-backend/models"
 	"a-drive-backend/utils"
 )
@@ -127,4 +130,420 @@ func BrowseUserFiles(c *gin.Context) {
 		"folders": folders,
 		"files":   files,
 	})
-}
\ No newline at end of file
+}
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// UpdateUserRole assigns one of the defined roles to a user
+func UpdateUserRole(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser := c.MustGet("user").(models.User)
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := findManagedUser(c, db)
+	if !ok {
+		return
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", req.Role).First(&role).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+	if !authz.CanGrant(db, &currentUser, role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot assign a role with permissions you do not have"})
+		return
+	}
+	
+	if user.IsAdmin() && req.Role != authz.RoleAdmin && isLastActiveAdmin(db, user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot demote the last active admin"})
+		return
+	}
+
+	user.Role = req.Role
+	if err := db.Model(&user).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// DisableUser blocks a user from logging in; existing tokens stop working
+// on their next request
+func DisableUser(c *gin.Context) {
+	setUserDisabled(c, true)
+}
+
+// EnableUser lets a disabled user log in again
+func EnableUser(c *gin.Context) {
+	setUserDisabled(c, false)
+}
+
+func setUserDisabled(c *gin.Context, disabled bool) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUserID := c.MustGet("user_id").(uint)
+
+	user, ok := findManagedUser(c, db)
+	if !ok {
+		return
+	}
+
+	if disabled && user.ID == currentUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot disable your own account"})
+		return
+	}
+	if disabled && user.IsAdmin() && isLastActiveAdmin(db, user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot disable the last active admin"})
+		return
+	}
+
+	user.Disabled = disabled
+	if err := db.Model(&user).Update("disabled", disabled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ResetUserPassword sets a new password for a user without knowing the old one
+func ResetUserPassword(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := findManagedUser(c, db)
+	if !ok {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	if err := db.Model(&user).Update("password_hash", hashedPassword).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// DeleteUser removes an account. With ?transfer_to=<user id> the user's
+// folders and files are handed to another user inside a new top-level folder;
+// with ?purge=true they are deleted from disk. One of the two is required.
+func DeleteUser(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUserID := c.MustGet("user_id").(uint)
+
+	user, ok := findManagedUser(c, db)
+	if !ok {
+		return
+	}
+
+	if user.ID == currentUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete your own account"})
+		return
+	}
+	if user.IsAdmin() && isLastActiveAdmin(db, user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete the last active admin"})
+		return
+	}
+
+	transferTo := c.Query("transfer_to")
+	purge := c.Query("purge") == "true"
+	if (transferTo == "") == !purge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Specify either transfer_to or purge=true"})
+		return
+	}
+
+	if purge {
+		if err := purgeUser(db, user, currentUserID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "User and files deleted successfully"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(transferTo, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer_to user ID"})
+		return
+	}
+
+	var target models.User
+	if err := db.First(&target, uint(targetID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer target user not found"})
+		return
+	}
+	if target.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot transfer files to the user being deleted"})
+		return
+	}
+
+	folder, err := transferUserFiles(db, user, target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer files"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User deleted and files transferred successfully",
+		"folder":  folder,
+	})
+}
+
+// findManagedUser loads the user named by the :id parameter, writing the
+// error response itself when it cannot
+func findManagedUser(c *gin.Context, db *gorm.DB) (models.User, bool) {
+	var user models.User
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return user, false
+	}
+	if err := db.First(&user, uint(userID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// isLastActiveAdmin reports whether no other enabled admin would remain
+func isLastActiveAdmin(db *gorm.DB, user models.User) bool {
+	var others int64
+	db.Model(&models.User{}).
+		Where("role = ? AND disabled = ? AND id != ?", "admin", false, user.ID).
+		Count(&others)
+	return others == 0
+}
+
+func userStorageDir(userID uint) string {
+	return filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID))
+}
+
+// purgeUser deletes the user with all their rows and their storage directory.
+// Team drive content belongs to the drive and is handed to heirID instead.
+func purgeUser(db *gorm.DB, user models.User, heirID uint) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := reassignDriveContent(tx, user.ID, heirID); err != nil {
+			return err
+		}
+		
+		fileIDs := tx.Unscoped().Model(&models.File{}).Select("id").Where("user_id = ?", user.ID)
+		folderIDs := tx.Unscoped().Model(&models.Folder{}).Select("id").Where("user_id = ?", user.ID)
+		ownItems := tx.Where("item_type = ? AND item_id IN (?)", "file", fileIDs).
+			Or("item_type = ? AND item_id IN (?)", "folder", folderIDs)
+		shareIDs := tx.Unscoped().Model(&models.FileShare{}).Select("id").Where("file_id IN (?) OR folder_id IN (?)", fileIDs, folderIDs)
+
+		return runSteps(
+			func() *gorm.DB { return tx.Where("share_id IN (?)", shareIDs).Delete(&models.ShareAccess{}) },
+			func() *gorm.DB {
+				return tx.Unscoped().Where("file_id IN (?) OR folder_id IN (?) OR shared_by = ?", fileIDs, folderIDs, user.ID).Delete(&models.FileShare{})
+			},
+			func() *gorm.DB { return tx.Unscoped().Where("file_id IN (?)", fileIDs).Delete(&models.FileVersion{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Or(ownItems).Delete(&models.Favorite{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Or(ownItems).Delete(&models.RecentAccess{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.Change{}) },
+			func() *gorm.DB {
+				return tx.Where("user_id = ? OR folder_id IN (?)", user.ID, folderIDs).Delete(&models.VersioningPolicy{})
+			},
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.Notification{}) },
+			func() *gorm.DB { return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.File{}) },
+			func() *gorm.DB { return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Folder{}) },
+			func() *gorm.DB { return tx.Unscoped().Delete(&user) },
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The rows are gone, so a leftover directory is only wasted space
+	if err := os.RemoveAll(userStorageDir(user.ID)); err != nil {
+		logging.FromContext(db.Statement.Context).Error("Failed to remove storage of deleted user", "deleted_user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// transferUserFiles moves everything the user owns into a new top-level
+// folder of the target user, then deletes the user. The user's storage
+// directory becomes that folder's physical directory.
+func transferUserFiles(db *gorm.DB, user, target models.User) (*models.Folder, error) {
+	name := uniqueRootFolderName(db, target.ID, user.Username+" (transferred)")
+	folder := models.Folder{
+		Name:   name,
+		UserID: target.ID,
+		Path:   name,
+	}
+
+	sourceDir := userStorageDir(user.ID)
+	targetDir := filepath.Join(userStorageDir(target.ID), folder.Path)
+	if err := os.MkdirAll(filepath.Dir(targetDir), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(sourceDir, targetDir); err != nil {
+		return nil, err
+	}
+
+	sourcePrefix := sourceDir + string(filepath.Separator)
+	targetPrefix := targetDir + string(filepath.Separator)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := reassignDriveContent(tx, user.ID, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Create(&folder).Error; err != nil {
+			return err
+		}
+
+		var folders []models.Folder
+		if err := tx.Unscoped().Where("user_id = ? AND drive_id IS NULL", user.ID).Find(&folders).Error; err != nil {
+			return err
+		}
+		for _, f := range folders {
+			updates := map[string]interface{}{
+				"user_id": target.ID,
+				"path":    filepath.Join(folder.Path, f.Path),
+			}
+			if f.ParentID == nil {
+				updates["parent_id"] = folder.ID
+			}
+			if err := tx.Unscoped().Model(&models.Folder{}).Where("id = ?", f.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		var files []models.File
+		if err := tx.Unscoped().Where("user_id = ? AND drive_id IS NULL", user.ID).Find(&files).Error; err != nil {
+			return err
+		}
+		for _, f := range files {
+			updates := map[string]interface{}{
+				"user_id":   target.ID,
+				"file_path": rebasePath(f.FilePath, sourcePrefix, targetPrefix),
+			}
+			if f.FolderID == nil {
+				updates["folder_id"] = folder.ID
+			}
+			if err := tx.Unscoped().Model(&models.File{}).Where("id = ?", f.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+
+			var versions []models.FileVersion
+			if err := tx.Unscoped().Where("file_id = ?", f.ID).Find(&versions).Error; err != nil {
+				return err
+			}
+			for _, v := range versions {
+				if err := tx.Unscoped().Model(&models.FileVersion{}).Where("id = ?", v.ID).
+					Update("file_path", rebasePath(v.FilePath, sourcePrefix, targetPrefix)).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// Folder policies move with the folders; the user's own default goes
+		return runSteps(
+			func() *gorm.DB {
+				return tx.Model(&models.FileShare{}).Where("shared_by = ?", user.ID).Update("shared_by", target.ID)
+			},
+			func() *gorm.DB {
+				return tx.Model(&models.FileVersion{}).Where("created_by = ?", user.ID).Update("created_by", target.ID)
+			},
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.Favorite{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.RecentAccess{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.Change{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.VersioningPolicy{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", user.ID).Delete(&models.Notification{}) },
+			func() *gorm.DB { return tx.Unscoped().Delete(&user) },
+		)
+	})
+	if err != nil {
+		// Put the directory back so the user is left as it was
+		if renameErr := os.Rename(targetDir, sourceDir); renameErr != nil {
+			logging.FromContext(db.Statement.Context).Error("Failed to restore storage of user", "target_user_id", user.ID, "error", renameErr)
+		}
+		return nil, err
+	}
+
+	recordFolderChange(db, &folder, "create")
+	return &folder, nil
+}
+
+// reassignDriveContent hands the team drive items, shares and journal entries
+// of a departing user to another user and drops their memberships
+func reassignDriveContent(tx *gorm.DB, from, to uint) error {
+	driveFileIDs := tx.Unscoped().Model(&models.File{}).Select("id").Where("drive_id IS NOT NULL")
+	driveFolderIDs := tx.Unscoped().Model(&models.Folder{}).Select("id").Where("drive_id IS NOT NULL")
+	return runSteps(
+		func() *gorm.DB {
+			return tx.Model(&models.FileShare{}).
+				Where("shared_by = ? AND (file_id IN (?) OR folder_id IN (?))", from, driveFileIDs, driveFolderIDs).
+				Update("shared_by", to)
+		},
+		func() *gorm.DB {
+			return tx.Unscoped().Model(&models.File{}).Where("user_id = ? AND drive_id IS NOT NULL", from).Update("user_id", to)
+		},
+		func() *gorm.DB {
+			return tx.Unscoped().Model(&models.Folder{}).Where("user_id = ? AND drive_id IS NOT NULL", from).Update("user_id", to)
+		},
+		func() *gorm.DB {
+			return tx.Model(&models.Change{}).Where("user_id = ? AND drive_id IS NOT NULL", from).Update("user_id", to)
+		},
+		func() *gorm.DB { return tx.Where("user_id = ?", from).Delete(&models.GroupMember{}) },
+		func() *gorm.DB { return tx.Where("user_id = ?", from).Delete(&models.TeamDriveMember{}) },
+	)
+}
+
+// runSteps runs statements in order and stops at the first that fails, so
+// that none runs after an error
+func runSteps(steps ...func() *gorm.DB) error {
+	for _, step := range steps {
+		if err := step().Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueRootFolderName appends " (2)", " (3)", ... until no top-level folder
+// of the user has the name
+func uniqueRootFolderName(db *gorm.DB, userID uint, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		var count int64
+		db.Model(&models.Folder{}).Where("user_id = ? AND drive_id IS NULL AND parent_id IS NULL AND name = ?", userID, candidate).Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+}
+
+// rebasePath swaps the directory prefix of a stored file path
+func rebasePath(path, oldPrefix, newPrefix string) string {
+	if strings.HasPrefix(path, oldPrefix) {
+		return newPrefix + strings.TrimPrefix(path, oldPrefix)
+	}
+	return path
+}
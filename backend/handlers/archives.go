@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"a-drive-backend/config"
+)
+
+var (
+	errUnsupportedArchive = errors.New("unsupported archive format, use .zip, .tar, .tar.gz or .tgz")
+	errUnsafePath         = errors.New("path must be relative and must not contain ..")
+	errArchiveLimit       = errors.New("archive exceeds the extraction limits")
+)
+
+// archiveLimits guard extraction against archive bombs
+type archiveLimits struct {
+	MaxEntries int
+	MaxSize    int64
+	MaxRatio   int64
+}
+
+func loadArchiveLimits() archiveLimits {
+	cfg := config.Load()
+	return archiveLimits{
+		MaxEntries: cfg.ArchiveMaxEntries,
+		MaxSize:    cfg.ArchiveMaxSize,
+		MaxRatio:   cfg.ArchiveMaxRatio,
+	}
+}
+
+// archiveEntry is one member of an archive. Open is only valid inside the
+// walkArchive callback, because tar members are read as a stream.
+type archiveEntry struct {
+	Name     string
+	Dir      bool
+	Size     int64
+	Modified time.Time
+	Open     func() (io.ReadCloser, error)
+}
+
+// isArchiveName reports whether walkArchive can read a file with this name
+func isArchiveName(name string) bool {
+	return archiveFormat(name) != ""
+}
+
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// walkArchive calls fn for every member of a ZIP or tar archive, choosing
+// the format by name. Symlinks and other special members are skipped.
+func walkArchive(r io.ReaderAt, size int64, name string, fn func(archiveEntry) error) error {
+	switch archiveFormat(name) {
+	case "zip":
+		return walkZip(r, size, fn)
+	case "tar.gz":
+		gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return walkTar(gz, fn)
+	case "tar":
+		return walkTar(io.NewSectionReader(r, 0, size), fn)
+	}
+	return errUnsupportedArchive
+}
+
+func walkZip(r io.ReaderAt, size int64, fn func(archiveEntry) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue
+		}
+		if err := fn(archiveEntry{
+			Name:     f.Name,
+			Dir:      mode.IsDir(),
+			Size:     int64(f.UncompressedSize64),
+			Modified: f.Modified,
+			Open:     f.Open,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(r io.Reader, fn func(archiveEntry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(archiveEntry{
+			Name:     header.Name,
+			Dir:      header.Typeflag == tar.TypeDir,
+			Size:     header.Size,
+			Modified: header.ModTime,
+			Open:     func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// scanArchive checks the members that include selects against the limits
+// before anything is extracted, and returns their total uncompressed size.
+// Declared sizes can be trusted: the ZIP and tar readers fail on members
+// that do not match them.
+func scanArchive(r io.ReaderAt, size int64, name string, include func(string) bool, limits archiveLimits) (int64, error) {
+	var entries int
+	var total int64
+	err := walkArchive(r, size, name, func(entry archiveEntry) error {
+		if !include(entry.Name) {
+			return nil
+		}
+		entries++
+		total += entry.Size
+		return limits.check(entries, total, size)
+	})
+	return total, err
+}
+
+// check fails when entries members of total uncompressed bytes, read from an
+// archive of size bytes, exceed the limits
+func (l archiveLimits) check(entries int, total, size int64) error {
+	switch {
+	case entries > l.MaxEntries:
+		return fmt.Errorf("%w: more than %d members", errArchiveLimit, l.MaxEntries)
+	case total > l.MaxSize:
+		return fmt.Errorf("%w: more than %d bytes uncompressed", errArchiveLimit, l.MaxSize)
+	case size > 0 && total/size > l.MaxRatio:
+		return fmt.Errorf("%w: compression ratio above %d", errArchiveLimit, l.MaxRatio)
+	}
+	return nil
+}
+
+// splitRelativePath splits a slash or backslash separated relative path into
+// its names, rejecting absolute paths and .. so that nothing escapes the
+// target folder
+func splitRelativePath(path string) ([]string, error) {
+	path = strings.ReplaceAll(path, "\\", "/")
+	if strings.HasPrefix(path, "/") || (len(path) > 1 && path[1] == ':') {
+		return nil, errUnsafePath
+	}
+
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			return nil, errUnsafePath
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, errUnsafePath
+	}
+	return names, nil
+}
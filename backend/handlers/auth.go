@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 	"a-drive-backend/utils"
 )
@@ -100,6 +101,11 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
+
 	token, err := utils.GenerateToken(user.ID, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -118,14 +124,16 @@ func Login(c *gin.Context) {
 }
 
 func GetMe(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 	user := c.MustGet("user").(models.User)
 	
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-			"role":     user.Role,
+			"id":          user.ID,
+			"username":    user.Username,
+			"email":       user.Email,
+			"role":        user.Role,
+			"permissions": authz.Permissions(db, &user),
 		},
 	})
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"archive/zip"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 )
 
@@ -19,6 +21,7 @@ type BulkOperationRequest struct {
 	FolderIDs []uint `json:"folder_ids"`
 	Action    string `json:"action"` // "delete", "move", "download"
 	TargetID  *uint  `json:"target_id,omitempty"` // for move operation
+	OnConflict string `json:"on_conflict" binding:"omitempty,oneof=reject keep_both overwrite skip"` // for move operation
 }
 
 type BulkOperationResult struct {
@@ -54,7 +57,7 @@ func BulkOperation(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Target folder ID required for move operation"})
 			return
 		}
-		result = bulkMove(db, userID, req.FileIDs, req.FolderIDs, *req.TargetID)
+		result = bulkMove(db, userID, req.FileIDs, req.FolderIDs, *req.TargetID, req.OnConflict)
 	case "download":
 		// For download, create a ZIP file with all selected items
 		result = bulkDownload(c, db, userID, req.FileIDs, req.FolderIDs)
@@ -72,8 +75,8 @@ func bulkDelete(db *gorm.DB, userID uint, fileIDs, folderIDs []uint) BulkOperati
 	
 	// Delete files
 	for _, fileID := range fileIDs {
-		var file models.File
-		if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
+		file, err := findFile(db, fileID, userID, authz.FilesWrite)
+		if err != nil {
 			result.Failed++
 			result.FailedItems = append(result.FailedItems, fmt.Sprintf("file_%d", fileID))
 			continue
@@ -93,20 +96,21 @@ func bulkDelete(db *gorm.DB, userID uint, fileIDs, folderIDs []uint) BulkOperati
 			continue
 		}
 		
+		recordFileChange(db, &file, "delete")
 		result.Processed++
 	}
 	
 	// Delete folders
 	for _, folderID := range folderIDs {
-		var folder models.Folder
-		if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+		folder, err := findFolder(db, folderID, userID, authz.FilesWrite)
+		if err != nil {
 			result.Failed++
 			result.FailedItems = append(result.FailedItems, fmt.Sprintf("folder_%d", folderID))
 			continue
 		}
 		
 		// Delete physical folder
-		physicalPath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), folder.Path)
+		physicalPath := folderStoragePath(&folder)
 		if err := os.RemoveAll(physicalPath); err != nil {
 			result.Failed++
 			result.FailedItems = append(result.FailedItems, folder.Name)
@@ -120,6 +124,7 @@ func bulkDelete(db *gorm.DB, userID uint, fileIDs, folderIDs []uint) BulkOperati
 			continue
 		}
 		
+		recordFolderChange(db, &folder, "delete")
 		result.Processed++
 	}
 	
@@ -131,75 +136,70 @@ func bulkDelete(db *gorm.DB, userID uint, fileIDs, folderIDs []uint) BulkOperati
 	return result
 }
 
-func bulkMove(db *gorm.DB, userID uint, fileIDs, folderIDs []uint, targetFolderID uint) BulkOperationResult {
+func bulkMove(db *gorm.DB, userID uint, fileIDs, folderIDs []uint, targetFolderID uint, policy string) BulkOperationResult {
 	result := BulkOperationResult{Success: true}
 	
-	// Verify target folder exists and belongs to user
+	// Verify target folder exists and the user may add to it
 	var targetFolder models.Folder
 	if targetFolderID > 0 {
-		if err := db.Where("id = ? AND user_id = ?", targetFolderID, userID).First(&targetFolder).Error; err != nil {
+		var err error
+		if targetFolder, err = findFolder(db, targetFolderID, userID, authz.FilesWrite); err != nil {
 			result.Success = false
 			result.Message = "Target folder not found"
 			return result
 		}
 	}
 	
+	// Items stay in their space; moving to the root means the root of that space
+	destFor := func(driveID *uint) destination {
+		if targetFolderID == 0 {
+			return destination{driveID: driveID}
+		}
+		return destination{folder: &targetFolder, driveID: targetFolder.DriveID}
+	}
+	
+	fail := func(name string, err error) {
+		result.Failed++
+		switch {
+		case errors.Is(err, errCrossSpace):
+			name += " (different drive)"
+		case errors.Is(err, errIntoItself):
+			name += " (circular dependency)"
+		case errors.Is(err, errNameConflict), errors.Is(err, errReplaceOwn):
+			name += " (name conflict)"
+		}
+		result.FailedItems = append(result.FailedItems, name)
+	}
+	
 	// Move files
 	for _, fileID := range fileIDs {
-		var file models.File
-		if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
+		file, err := findFile(db, fileID, userID, authz.FilesWrite)
+		if err != nil {
 			result.Failed++
 			result.FailedItems = append(result.FailedItems, fmt.Sprintf("file_%d", fileID))
 			continue
 		}
 		
-		// Update folder_id
-		if targetFolderID == 0 {
-			file.FolderID = nil // Move to root
-		} else {
-			file.FolderID = &targetFolderID
-		}
-		
-		if err := db.Save(&file).Error; err != nil {
-			result.Failed++
-			result.FailedItems = append(result.FailedItems, file.Name)
+		if _, _, err := moveFileTo(db, userID, file, destFor(file.DriveID), policy); err != nil {
+			fail(file.Name, err)
 			continue
 		}
-		
 		result.Processed++
 	}
 	
 	// Move folders
 	for _, folderID := range folderIDs {
-		var folder models.Folder
-		if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+		folder, err := findFolder(db, folderID, userID, authz.FilesWrite)
+		if err != nil {
 			result.Failed++
 			result.FailedItems = append(result.FailedItems, fmt.Sprintf("folder_%d", folderID))
 			continue
 		}
 		
-		// Check for circular dependency (moving folder into itself or its descendant)
-		if targetFolderID > 0 && isDescendantOf(db, targetFolderID, folderID) {
-			result.Failed++
-			result.FailedItems = append(result.FailedItems, fmt.Sprintf("%s (circular dependency)", folder.Name))
+		if _, _, err := moveFolderTo(db, userID, folder, destFor(folder.DriveID), policy); err != nil {
+			fail(folder.Name, err)
 			continue
 		}
-		
-		// Update parent_id
-		if targetFolderID == 0 {
-			folder.ParentID = nil // Move to root
-			folder.Path = folder.Name
-		} else {
-			folder.ParentID = &targetFolderID
-			folder.Path = filepath.Join(targetFolder.Path, folder.Name)
-		}
-		
-		if err := db.Save(&folder).Error; err != nil {
-			result.Failed++
-			result.FailedItems = append(result.FailedItems, folder.Name)
-			continue
-		}
-		
 		result.Processed++
 	}
 	
@@ -232,8 +232,8 @@ func bulkDownload(c *gin.Context, db *gorm.DB, userID uint, fileIDs, folderIDs [
 	
 	// Add files to ZIP
 	for _, fileID := range fileIDs {
-		var file models.File
-		if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
+		file, err := findFile(db, fileID, userID, authz.FilesRead)
+		if err != nil {
 			continue
 		}
 		
@@ -258,43 +258,12 @@ func bulkDownload(c *gin.Context, db *gorm.DB, userID uint, fileIDs, folderIDs [
 	
 	// Add folders to ZIP
 	for _, folderID := range folderIDs {
-		var folder models.Folder
-		if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+		folder, err := findFolder(db, folderID, userID, authz.FilesRead)
+		if err != nil {
 			continue
 		}
 		
-		sourcePath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), folder.Path)
-		
-		err = filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			
-			relPath, err := filepath.Rel(sourcePath, path)
-			if err != nil {
-				return err
-			}
-			
-			if info.IsDir() {
-				return nil
-			}
-			
-			zipPath := filepath.Join(folder.Name, relPath)
-			
-			file, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-			
-			writer, err := zipWriter.Create(zipPath)
-			if err != nil {
-				return err
-			}
-			
-			_, err = io.Copy(writer, file)
-			return err
-		})
+		err = addFolderToZip(db, zipWriter, folder, folder.Name)
 		
 		if err == nil {
 			result.Processed++
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/logging"
+	"a-drive-backend/models"
+)
+
+const (
+	defaultChangesPageSize = 100
+	maxChangesPageSize     = 1000
+)
+
+// GetChanges returns journal entries recorded after the given cursor, oldest
+// first. The feed covers the personal space, or a team drive with drive_id.
+func GetChanges(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	scope, ok := changeScope(c, db)
+	if !ok {
+		return
+	}
+
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChangesPageSize)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > maxChangesPageSize {
+		limit = maxChangesPageSize
+	}
+
+	// Fetch one extra row to know whether another page follows
+	var changes []models.Change
+	if err := scope.Where("id > ?", cursor).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch changes"})
+		return
+	}
+
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	nextCursor := cursor
+	if len(changes) > 0 {
+		nextCursor = uint64(changes[len(changes)-1].ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"changes":  changes,
+		"cursor":   nextCursor,
+		"has_more": hasMore,
+	})
+}
+
+// GetLatestChangeCursor returns the cursor of the newest journal entry so a
+// client can start following changes without replaying history
+func GetLatestChangeCursor(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	scope, ok := changeScope(c, db)
+	if !ok {
+		return
+	}
+
+	var cursor uint64
+	if err := scope.Model(&models.Change{}).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&cursor).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch latest cursor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cursor": cursor})
+}
+
+// changeScope restricts the journal to the space selected by the drive_id
+// query parameter
+func changeScope(c *gin.Context, db *gorm.DB) (*gorm.DB, bool) {
+	userID := c.MustGet("user_id").(uint)
+
+	driveID, err := parseDriveID(c.Query("drive_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return nil, false
+	}
+	if !authorizeSpace(c, db, driveID, authz.FilesRead) {
+		return nil, false
+	}
+	return personalOrDrive(db, userID, driveID), true
+}
+
+// recordFileChange appends a journal entry for a file
+func recordFileChange(db *gorm.DB, file *models.File, action string) {
+	recordChange(db, models.Change{
+		UserID:   file.UserID,
+		DriveID:  file.DriveID,
+		ItemType: "file",
+		ItemID:   file.ID,
+		Action:   action,
+		Name:     file.Name,
+		ParentID: file.FolderID,
+	})
+}
+
+// recordFolderChange appends a journal entry for a folder. Deleting a folder
+// implies the deletion of everything below it.
+func recordFolderChange(db *gorm.DB, folder *models.Folder, action string) {
+	recordChange(db, models.Change{
+		UserID:   folder.UserID,
+		DriveID:  folder.DriveID,
+		ItemType: "folder",
+		ItemID:   folder.ID,
+		Action:   action,
+		Name:     folder.Name,
+		ParentID: folder.ParentID,
+	})
+}
+
+func recordChange(db *gorm.DB, change models.Change) {
+	if err := db.Create(&change).Error; err != nil {
+		logging.FromContext(db.Statement.Context).Error("Failed to record change", "action", change.Action, "item_type", change.ItemType, "item_id", change.ItemID, "error", err)
+	}
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/logging"
+	"a-drive-backend/models"
+)
+
+type CreateDriveRequest struct {
+	Name        string `json:"name" binding:"required"`
+	GroupID     uint   `json:"group_id" binding:"required"`
+	QuotaBytes  int64  `json:"quota_bytes" binding:"min=0"`
+	DefaultRole string `json:"default_role" binding:"omitempty,oneof=reader contributor manager"`
+}
+
+type UpdateDriveRequest struct {
+	Name        string `json:"name"`
+	QuotaBytes  *int64 `json:"quota_bytes" binding:"omitempty,min=0"`
+	DefaultRole string `json:"default_role" binding:"omitempty,oneof=reader contributor manager"`
+}
+
+type SetDriveMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=reader contributor manager"`
+}
+
+// DriveMember is a group member together with their effective drive role
+type DriveMember struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Override bool   `json:"override"` // the role differs from the drive default on purpose
+}
+
+// CreateDrive creates a team drive owned by a group the user administers
+func CreateDrive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	var req CreateDriveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var group models.Group
+	if err := db.First(&group, req.GroupID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	isAdmin, isMember := authz.GroupMembership(db, group.ID, userID)
+	if !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only group admins can create team drives"})
+		return
+	}
+
+	drive := models.TeamDrive{
+		Name:        req.Name,
+		GroupID:     group.ID,
+		QuotaBytes:  req.QuotaBytes,
+		DefaultRole: req.DefaultRole,
+		CreatedBy:   userID,
+	}
+	if drive.DefaultRole == "" {
+		drive.DefaultRole = models.DriveRoleContributor
+	}
+
+	if err := db.Omit("Group").Create(&drive).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team drive"})
+		return
+	}
+
+	if err := os.MkdirAll(storageRoot(userID, &drive.ID), 0755); err != nil {
+		db.Unscoped().Delete(&drive)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team drive directory"})
+		return
+	}
+
+	drive.Group = group
+	drive.Role = models.DriveRoleManager
+	c.JSON(http.StatusCreated, gin.H{"drive": drive})
+}
+
+// ListDrives returns the team drives the user can access with their role and usage
+func ListDrives(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	var drives []models.TeamDrive
+	if err := db.Preload("Group").
+		Where("id IN (?)", authz.ReadableDriveIDs(db, userID)).
+		Order("name ASC").
+		Find(&drives).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team drives"})
+		return
+	}
+
+	for i := range drives {
+		drives[i].Role = authz.DriveRole(db, drives[i].ID, userID)
+		drives[i].UsedBytes = driveUsage(db, drives[i].ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drives": drives})
+}
+
+// GetDrive returns a team drive with the effective role of every group member
+func GetDrive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	drive, ok := loadDrive(c, db, authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	var groupMembers []models.GroupMember
+	if err := db.Preload("User").Where("group_id = ?", drive.GroupID).Find(&groupMembers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
+		return
+	}
+
+	var overrides []models.TeamDriveMember
+	db.Where("drive_id = ?", drive.ID).Find(&overrides)
+	overrideRoles := make(map[uint]string)
+	for _, o := range overrides {
+		overrideRoles[o.UserID] = o.Role
+	}
+
+	members := make([]DriveMember, 0, len(groupMembers))
+	for _, m := range groupMembers {
+		member := DriveMember{UserID: m.UserID, Username: m.User.Username, Role: drive.DefaultRole}
+		if role, ok := overrideRoles[m.UserID]; ok {
+			member.Role = role
+			member.Override = true
+		}
+		if m.IsAdmin {
+			member.Role = models.DriveRoleManager
+		}
+		members = append(members, member)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drive": drive, "members": members})
+}
+
+// UpdateDrive renames a team drive or changes its quota or default role
+func UpdateDrive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req UpdateDriveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	drive, ok := loadDrive(c, db, authz.DrivesManage)
+	if !ok {
+		return
+	}
+
+	if req.Name != "" {
+		drive.Name = req.Name
+	}
+	if req.QuotaBytes != nil {
+		drive.QuotaBytes = *req.QuotaBytes
+	}
+	if req.DefaultRole != "" {
+		drive.DefaultRole = req.DefaultRole
+	}
+
+	if err := db.Omit("Group").Save(&drive).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team drive"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drive": drive})
+}
+
+// DeleteDrive deletes an empty team drive
+func DeleteDrive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	drive, ok := loadDrive(c, db, authz.DrivesManage)
+	if !ok {
+		return
+	}
+
+	var files, folders int64
+	db.Model(&models.File{}).Where("drive_id = ?", drive.ID).Count(&files)
+	db.Model(&models.Folder{}).Where("drive_id = ?", drive.ID).Count(&folders)
+	if files > 0 || folders > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Team drive is not empty"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("drive_id = ?", drive.ID).Delete(&models.TeamDriveMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.TeamDrive{}, drive.ID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team drive"})
+		return
+	}
+
+	if err := os.RemoveAll(storageRoot(userID, &drive.ID)); err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to remove storage of team drive", "drive_id", drive.ID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team drive deleted successfully"})
+}
+
+// SetDriveMember gives a group member a role in the drive other than the default
+func SetDriveMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req SetDriveMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	drive, ok := loadDrive(c, db, authz.DrivesManage)
+	if !ok {
+		return
+	}
+
+	groupMember, ok := findGroupMember(c, db, drive.GroupID)
+	if !ok {
+		return
+	}
+
+	var member models.TeamDriveMember
+	err := db.Where("drive_id = ? AND user_id = ?", drive.ID, groupMember.UserID).First(&member).Error
+	if err != nil {
+		member = models.TeamDriveMember{DriveID: drive.ID, UserID: groupMember.UserID}
+	}
+	member.Role = req.Role
+
+	if err := db.Omit("User").Save(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member role"})
+		return
+	}
+	member.User = groupMember.User
+
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+// RemoveDriveMember drops a member's role override so the default applies again
+func RemoveDriveMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	drive, ok := loadDrive(c, db, authz.DrivesManage)
+	if !ok {
+		return
+	}
+
+	result := db.Where("drive_id = ? AND user_id = ?", drive.ID, c.Param("user_id")).Delete(&models.TeamDriveMember{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member role"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member has no role override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member role reset to the drive default"})
+}
+
+// loadDrive loads the team drive named by the :id parameter, writing the
+// error response itself when the user lacks the permission there
+func loadDrive(c *gin.Context, db *gorm.DB, permission authz.Permission) (models.TeamDrive, bool) {
+	userID := c.MustGet("user_id").(uint)
+
+	var drive models.TeamDrive
+	if err := db.Preload("Group").Where("id = ?", c.Param("id")).First(&drive).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team drive not found"})
+		return drive, false
+	}
+	if err := authz.CheckDrive(db, drive.ID, userID, permission); err != nil {
+		respondAccessError(c, err, "Team drive not found")
+		return drive, false
+	}
+
+	drive.Role = authz.DriveRole(db, drive.ID, userID)
+	drive.UsedBytes = driveUsage(db, drive.ID)
+	return drive, true
+}
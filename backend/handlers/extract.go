@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+var (
+	errMemberFound = errors.New("member found")
+	errWalkDone    = errors.New("enough members listed")
+)
+
+// ArchiveMember is one entry of an archive listing
+type ArchiveMember struct {
+	Path     string    `json:"path"`
+	Dir      bool      `json:"dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// ExtractArchiveRequest names the destination of an extraction like a copy,
+// and optionally the members to extract. A directory selects everything below it.
+type ExtractArchiveRequest struct {
+	TransferRequest
+	Members []string `json:"members"`
+}
+
+// ListArchive lists the members of a ZIP or tar file without extracting it
+func ListArchive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	file, src, info, ok := openArchiveFile(c, db)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	limits := loadArchiveLimits()
+	members := []ArchiveMember{}
+	truncated := false
+	var total int64
+	err := walkArchive(src, info.Size(), file.Name, func(entry archiveEntry) error {
+		if len(members) == limits.MaxEntries {
+			truncated = true
+			return errWalkDone
+		}
+		total += entry.Size
+		members = append(members, ArchiveMember{
+			Path:     strings.TrimSuffix(entry.Name, "/"),
+			Dir:      entry.Dir,
+			Size:     entry.Size,
+			Modified: entry.Modified,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, errWalkDone) {
+		respondArchiveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":       file,
+		"members":    members,
+		"truncated":  truncated,
+		"total_size": total,
+	})
+}
+
+// DownloadArchiveMember streams one file out of a ZIP or tar file
+func DownloadArchiveMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	want := cleanMemberPath(c.Query("path"))
+	if want == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+		return
+	}
+
+	file, src, info, ok := openArchiveFile(c, db)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	limits := loadArchiveLimits()
+	err := walkArchive(src, info.Size(), file.Name, func(entry archiveEntry) error {
+		if entry.Dir || cleanMemberPath(entry.Name) != want {
+			return nil
+		}
+		if err := limits.check(1, entry.Size, info.Size()); err != nil {
+			return err
+		}
+		content, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		contentType := mime.TypeByExtension(path.Ext(want))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.DataFromReader(http.StatusOK, entry.Size, contentType, io.LimitReader(content, entry.Size), map[string]string{
+			"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, path.Base(want)),
+		})
+		return errMemberFound
+	})
+	switch {
+	case errors.Is(err, errMemberFound):
+	case err != nil:
+		respondArchiveError(c, err)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Archive member not found"})
+	}
+}
+
+// ExtractArchive unpacks a ZIP or tar file, or the selected members, into a
+// folder as regular files and folders owned by the user
+func ExtractArchive(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	var req ExtractArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dest, ok := transferDestination(c, db, req.TransferRequest)
+	if !ok {
+		return
+	}
+
+	file, src, info, ok := openArchiveFile(c, db)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	include := func(string) bool { return true }
+	if len(req.Members) > 0 {
+		selected := make([]string, len(req.Members))
+		for i, m := range req.Members {
+			selected[i] = cleanMemberPath(m)
+		}
+		include = func(name string) bool {
+			name = cleanMemberPath(name)
+			for _, s := range selected {
+				if name == s || strings.HasPrefix(name, s+"/") {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// Extracted files are uploads; the binding has validated the policy
+	policy, _ := parseConflictPolicy(req.OnConflict)
+	batch := newBatchUpload(db, userID, dest, policy)
+	if err := batch.extract(src, info.Size(), file.Name, include); err != nil {
+		respondArchiveError(c, err)
+		return
+	}
+
+	result := batch.result
+	result.Success = result.Failed == 0
+	result.Message = fmt.Sprintf("Extracted %d files, %d failed", result.Processed, result.Failed)
+	c.JSON(http.StatusOK, result)
+}
+
+// openArchiveFile loads the archive named by the :id parameter and opens its
+// content, writing the error response itself
+func openArchiveFile(c *gin.Context, db *gorm.DB) (models.File, *os.File, os.FileInfo, bool) {
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return file, nil, nil, false
+	}
+	if !isArchiveName(file.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnsupportedArchive.Error()})
+		return file, nil, nil, false
+	}
+
+	src, err := os.Open(file.FilePath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open archive"})
+		return file, nil, nil, false
+	}
+	info, err := src.Stat()
+	if err != nil {
+		src.Close()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open archive"})
+		return file, nil, nil, false
+	}
+	return file, src, info, true
+}
+
+// cleanMemberPath normalizes a member path for comparisons
+func cleanMemberPath(name string) string {
+	name = strings.Trim(filepath.ToSlash(strings.ReplaceAll(name, "\\", "/")), "/")
+	if name == "" {
+		return ""
+	}
+	return path.Clean(name)
+}
@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 )
 
+type FavoriteItemRequest struct {
+	ItemType string `json:"item_type" binding:"required,oneof=file folder"`
+	ItemID   uint   `json:"item_id" binding:"required"`
+}
+
 // GetFavorites returns all favorites for the authenticated user
 func GetFavorites(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
@@ -33,14 +40,12 @@ func GetFavorites(c *gin.Context) {
 		}
 
 		if fav.ItemType == "file" {
-			var file models.File
-			if err := db.Where("id = ? AND user_id = ?", fav.ItemID, userID).First(&file).Error; err == nil {
+			if file, err := findFile(db, fav.ItemID, userID, authz.FilesRead); err == nil {
 				file.IsFavorite = true
 				favoriteData["item"] = file
 			}
 		} else if fav.ItemType == "folder" {
-			var folder models.Folder
-			if err := db.Where("id = ? AND user_id = ?", fav.ItemID, userID).First(&folder).Error; err == nil {
+			if folder, err := findFolder(db, fav.ItemID, userID, authz.FilesRead); err == nil {
 				folder.IsFavorite = true
 				favoriteData["item"] = folder
 			}
@@ -57,27 +62,20 @@ func AddFavorite(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 
-	var req struct {
-		ItemType string `json:"item_type" binding:"required,oneof=file folder"`
-		ItemID   uint   `json:"item_id" binding:"required"`
-	}
+	var req FavoriteItemRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Verify the item exists and belongs to the user
+	// Verify the item exists and the user can see it
 	if req.ItemType == "file" {
-		var file models.File
-		if err := db.Where("id = ? AND user_id = ?", req.ItemID, userID).First(&file).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		if _, ok := loadFile(c, db, req.ItemID, authz.FilesRead); !ok {
 			return
 		}
 	} else {
-		var folder models.Folder
-		if err := db.Where("id = ? AND user_id = ?", req.ItemID, userID).First(&folder).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		if _, ok := loadFolder(c, db, req.ItemID, authz.FilesRead); !ok {
 			return
 		}
 	}
@@ -97,6 +95,10 @@ func AddFavorite(c *gin.Context) {
 	}
 
 	if err := db.Create(&favorite).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Item is already favorited"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add favorite"})
 		return
 	}
@@ -171,10 +173,7 @@ func RemoveFavoriteByItem(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 
-	var req struct {
-		ItemType string `json:"item_type" binding:"required,oneof=file folder"`
-		ItemID   uint   `json:"item_id" binding:"required"`
-	}
+	var req FavoriteItemRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
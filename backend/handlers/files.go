@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"crypto/md5"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,20 +12,46 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 )
 
+type RenameFileRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
 func ListFiles(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 	
 	folderID := c.Query("folder_id")
 	
+	driveID, err := parseDriveID(c.Query("drive_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+	if driveID != nil {
+		if !authorizeSpace(c, db, driveID, authz.FilesRead) {
+			return
+		}
+	} else if folderID != "" && folderID != "root" {
+		// Listing a drive folder by ID alone switches to that drive
+		folder, err := findFolder(db, folderID, userID, authz.FilesRead)
+		if err != nil {
+			respondAccessError(c, err, "Folder not found")
+			return
+		}
+		driveID = folder.DriveID
+	} else if !authorizeSpace(c, db, nil, authz.FilesRead) {
+		return
+	}
+	
 	var folders []models.Folder
 	var files []models.File
 	
-	folderQuery := db.Where("user_id = ?", userID)
-	fileQuery := db.Where("user_id = ?", userID)
+	folderQuery := personalOrDrive(db, userID, driveID)
+	fileQuery := personalOrDrive(db, userID, driveID)
 	
 	if folderID != "" {
 		if folderID == "root" {
@@ -83,63 +110,38 @@ func UploadFile(c *gin.Context) {
 	}
 	defer file.Close()
 	
-	folderIDStr := c.PostForm("folder_id")
-	var folderID *uint
-	if folderIDStr != "" && folderIDStr != "root" {
-		fID, err := strconv.ParseUint(folderIDStr, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
-			return
-		}
-		uid := uint(fID)
-		folderID = &uid
-	}
-	
-	userDir := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID))
-	fileName := fmt.Sprintf("%d_%s", userID, header.Filename)
-	filePath := filepath.Join(userDir, fileName)
-	
-	out, err := os.Create(filePath)
+	policy, err := parseConflictPolicy(c.PostForm("on_conflict"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create file"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer out.Close()
 	
-	_, err = io.Copy(out, file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+	dest, ok := uploadDestination(c, db)
+	if !ok {
 		return
 	}
 	
-	fileModel := models.File{
-		Name:         header.Filename,
-		OriginalName: header.Filename,
-		FolderID:     folderID,
-		UserID:       userID,
-		FilePath:     filePath,
-		Size:         header.Size,
-		MimeType:     header.Header.Get("Content-Type"),
-	}
-	
-	if err := db.Create(&fileModel).Error; err != nil {
-		os.Remove(filePath)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file metadata"})
+	stored, outcome, err := storeUpload(db, userID, upload{
+		Name:     header.Filename,
+		MimeType: partMimeType(header),
+		Size:     header.Size,
+		Content:  file,
+	}, dest, policy)
+	if err != nil {
+		respondTransferError(c, err, "Failed to save file")
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"file": fileModel})
+	c.JSON(http.StatusOK, gin.H{"file": stored, "result": outcome})
 }
 
 func DownloadFile(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesRead)
+	if !ok {
 		return
 	}
 	
@@ -149,13 +151,11 @@ func DownloadFile(c *gin.Context) {
 
 func DeleteFile(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
@@ -169,48 +169,122 @@ func DeleteFile(c *gin.Context) {
 		return
 	}
 	
+	recordFileChange(db, &file, "delete")
+	
 	c.JSON(http.StatusOK, gin.H{"message": "File deleted successfully"})
 }
 
 func RenameFile(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	var req struct {
-		Name string `json:"name" binding:"required"`
-	}
+	var req RenameFileRequest
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
-	
+
+	// A sibling with the new name would make the two indistinguishable
+	if req.Name != file.Name {
+		dest := destination{driveID: file.DriveID}
+		if file.FolderID != nil {
+			var folder models.Folder
+			if err := db.First(&folder, *file.FolderID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename file"})
+				return
+			}
+			dest.folder = &folder
+		}
+		if _, _, err := resolveFileName(db, file.UserID, dest, req.Name, file.ID, conflictReject); err != nil {
+			respondTransferError(c, err, "Failed to rename file")
+			return
+		}
+	}
+
 	file.Name = req.Name
 	if err := db.Save(&file).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename file"})
 		return
 	}
 	
+	recordFileChange(db, &file, "rename")
+	
+	c.JSON(http.StatusOK, gin.H{"file": file})
+}
+
+// ReplaceFileContent overwrites the content of an existing file in place,
+// keeping its ID, name and shares. Versioned files get a new version instead.
+func ReplaceFileContent(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	fileID := c.Param("id")
+
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
+		return
+	}
+
+	upload, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	defer upload.Close()
+
+	if err := replaceContent(db, userID, &file, upload, header.Size, partMimeType(header), c.PostForm("comment")); err != nil {
+		respondTransferError(c, err, "Failed to save file")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"file": file})
 }
 
+// writeFileContent atomically replaces the file at path with src and returns
+// the new size and MD5 checksum
+func writeFileContent(path string, src io.Reader) (int64, string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, "", err
+	}
+
+	return size, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
 // GetPhotos returns all image files for the authenticated user
 func GetPhotos(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 
+	if !authorizeSpace(c, db, nil, authz.FilesRead) {
+		return
+	}
+	
 	var files []models.File
 	
 	// Query for files with image MIME types
-	if err := db.Where("user_id = ? AND mime_type LIKE ?", userID, "image/%").
+	if err := db.Where("user_id = ? AND drive_id IS NULL AND mime_type LIKE ?", userID, "image/%").
 		Order("created_at DESC").
 		Find(&files).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+// BatchUploadItem is the outcome of one file of a batch upload
+type BatchUploadItem struct {
+	Path   string       `json:"path"`
+	Result string       `json:"result,omitempty"` // created, renamed, replaced or skipped
+	File   *models.File `json:"file,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// BatchUploadResult reports a batch upload like a bulk operation, with the
+// outcome of every file
+type BatchUploadResult struct {
+	BulkOperationResult
+	FoldersCreated int               `json:"folders_created"`
+	Items          []BatchUploadItem `json:"items"`
+}
+
+// UploadBatch uploads many files with relative paths, or the members of one
+// ZIP or tar archive, below a target folder. Missing folders are created and
+// existing ones are reused; on_conflict applies to every file.
+func UploadBatch(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+		return
+	}
+
+	policy, err := parseConflictPolicy(c.PostForm("on_conflict"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	files := form.File["files"]
+	archives := form.File["archive"]
+	if (len(files) == 0) == (len(archives) == 0) || len(archives) > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either files or a single archive"})
+		return
+	}
+	paths := form.Value["paths"]
+	if len(paths) > 0 && len(paths) != len(files) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "paths must list one path per file"})
+		return
+	}
+
+	dest, ok := uploadDestination(c, db)
+	if !ok {
+		return
+	}
+
+	batch := newBatchUpload(db, userID, dest, policy)
+	if len(archives) == 1 {
+		if !isArchiveName(archives[0].Filename) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errUnsupportedArchive.Error()})
+			return
+		}
+		if err := batch.addArchive(archives[0]); err != nil {
+			respondArchiveError(c, err)
+			return
+		}
+	} else {
+		for i, header := range files {
+			path := header.Filename
+			if len(paths) > 0 {
+				path = paths[i]
+			}
+			batch.addPart(path, header)
+		}
+	}
+
+	result := batch.result
+	result.Success = result.Failed == 0
+	result.Message = fmt.Sprintf("Uploaded %d files, %d failed", result.Processed, result.Failed)
+	c.JSON(http.StatusOK, result)
+}
+
+// uploadDestination reads the folder_id and drive_id form fields of an
+// upload. The folder decides the drive; drive_id only matters for a drive root.
+func uploadDestination(c *gin.Context, db *gorm.DB) (destination, bool) {
+	driveID, err := parseDriveID(c.PostForm("drive_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return destination{}, false
+	}
+
+	folderIDStr := c.PostForm("folder_id")
+	if folderIDStr == "" || folderIDStr == "root" {
+		if !authorizeSpace(c, db, driveID, authz.FilesWrite) {
+			return destination{}, false
+		}
+		return destination{driveID: driveID}, true
+	}
+
+	folderID, err := strconv.ParseUint(folderIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return destination{}, false
+	}
+	folder, ok := loadFolder(c, db, uint(folderID), authz.FilesWrite)
+	if !ok {
+		return destination{}, false
+	}
+	return destination{folder: &folder, driveID: folder.DriveID}, true
+}
+
+// batchUpload stores the files of one batch and remembers the folders it
+// has resolved, keyed by their path below the target
+type batchUpload struct {
+	db      *gorm.DB
+	userID  uint
+	dest    destination
+	policy  string
+	folders map[string]destination
+	result  BatchUploadResult
+}
+
+func newBatchUpload(db *gorm.DB, userID uint, dest destination, policy string) *batchUpload {
+	return &batchUpload{
+		db:      db,
+		userID:  userID,
+		dest:    dest,
+		policy:  policy,
+		folders: map[string]destination{"": dest},
+		result:  BatchUploadResult{Items: []BatchUploadItem{}},
+	}
+}
+
+func (b *batchUpload) addPart(path string, header *multipart.FileHeader) {
+	src, err := header.Open()
+	if err != nil {
+		b.fail(path, err)
+		return
+	}
+	defer src.Close()
+
+	b.addFile(path, upload{
+		MimeType: partMimeType(header),
+		Size:     header.Size,
+		Content:  src,
+	})
+}
+
+func (b *batchUpload) addArchive(header *multipart.FileHeader) error {
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return b.extract(src, header.Size, header.Filename, func(string) bool { return true })
+}
+
+// extract stores the archive members that include selects. The whole
+// selection is checked against the archive limits and the drive quota first.
+func (b *batchUpload) extract(r io.ReaderAt, size int64, name string, include func(string) bool) error {
+	total, err := scanArchive(r, size, name, include, loadArchiveLimits())
+	if err != nil {
+		return err
+	}
+	if err := checkDriveQuota(b.db, b.dest.driveID, total); err != nil {
+		return err
+	}
+
+	return walkArchive(r, size, name, func(entry archiveEntry) error {
+		if !include(entry.Name) {
+			return nil
+		}
+		if entry.Dir {
+			names, err := splitRelativePath(entry.Name)
+			if err == nil {
+				_, err = b.folder(names)
+			}
+			if err != nil {
+				b.fail(entry.Name, err)
+			}
+			return nil
+		}
+
+		content, err := entry.Open()
+		if err != nil {
+			b.fail(entry.Name, err)
+			return nil
+		}
+		defer content.Close()
+
+		b.addFile(entry.Name, upload{
+			MimeType: mime.TypeByExtension(filepath.Ext(entry.Name)),
+			Size:     entry.Size,
+			Content:  content,
+		})
+		return nil
+	})
+}
+
+// addFile stores one file at its relative path, creating missing folders
+func (b *batchUpload) addFile(path string, up upload) {
+	names, err := splitRelativePath(path)
+	if err != nil {
+		b.fail(path, err)
+		return
+	}
+
+	dest, err := b.folder(names[:len(names)-1])
+	if err != nil {
+		b.fail(path, err)
+		return
+	}
+
+	up.Name = names[len(names)-1]
+	file, outcome, err := storeUpload(b.db, b.userID, up, dest, b.policy)
+	if err != nil {
+		b.fail(path, err)
+		return
+	}
+
+	b.result.Processed++
+	b.result.Items = append(b.result.Items, BatchUploadItem{Path: path, Result: outcome, File: &file})
+}
+
+// folder resolves a folder below the target by names, reusing folders of the
+// same name and creating the missing ones
+func (b *batchUpload) folder(names []string) (destination, error) {
+	key := strings.Join(names, "/")
+	if dest, ok := b.folders[key]; ok {
+		return dest, nil
+	}
+
+	parent, err := b.folder(names[:len(names)-1])
+	if err != nil {
+		return parent, err
+	}
+
+	name := names[len(names)-1]
+	var folder models.Folder
+	query := personalOrDrive(b.db, b.userID, parent.driveID).Where("name = ?", name)
+	if err := whereParent(query, "parent_id", parent.folderID()).First(&folder).Error; err != nil {
+		folder = models.Folder{
+			Name:      name,
+			ParentID:  parent.folderID(),
+			DriveID:   parent.driveID,
+			UserID:    b.userID,
+			IconType:  "folder",
+			IconColor: "text-blue-500",
+			Path:      parent.path(name),
+		}
+		if err := b.db.Create(&folder).Error; err != nil {
+			return parent, err
+		}
+		if err := os.MkdirAll(folderStoragePath(&folder), 0755); err != nil {
+			b.db.Delete(&folder)
+			return parent, err
+		}
+		recordFolderChange(b.db, &folder, "create")
+		b.result.FoldersCreated++
+	}
+
+	dest := destination{folder: &folder, driveID: folder.DriveID}
+	b.folders[key] = dest
+	return dest, nil
+}
+
+func (b *batchUpload) fail(path string, err error) {
+	b.result.Failed++
+	b.result.FailedItems = append(b.result.FailedItems, path)
+	b.result.Items = append(b.result.Items, BatchUploadItem{Path: path, Error: uploadErrorMessage(err)})
+}
+
+// respondArchiveError writes the response for an archive that could not be
+// read or exceeds the limits
+func respondArchiveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errArchiveLimit):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, errQuotaExceeded):
+		respondQuotaError(c, err)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read archive: " + err.Error()})
+	}
+}
+
+// uploadErrorMessage is the user-facing text of an upload error
+func uploadErrorMessage(err error) string {
+	if errors.Is(err, errNameConflict) || errors.Is(err, errQuotaExceeded) || errors.Is(err, errUnsafePath) {
+		return err.Error()
+	}
+	return "Failed to save file"
+}
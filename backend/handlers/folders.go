@@ -6,25 +6,36 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 )
 
+type CreateFolderRequest struct {
+	Name      string `json:"name" binding:"required"`
+	ParentID  *uint  `json:"parent_id"`
+	DriveID   *uint  `json:"drive_id"` // create at the root of a team drive
+	IconType  string `json:"icon_type"`
+	IconColor string `json:"icon_color"`
+}
+
+type UpdateFolderRequest struct {
+	Name      string `json:"name"`
+	IconType  string `json:"icon_type"`
+	IconColor string `json:"icon_color"`
+}
+
 func CreateFolder(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 	
-	var req struct {
-		Name      string `json:"name" binding:"required"`
-		ParentID  *uint  `json:"parent_id"`
-		IconType  string `json:"icon_type"`
-		IconColor string `json:"icon_color"`
-	}
+	var req CreateFolderRequest
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -40,20 +51,26 @@ func CreateFolder(c *gin.Context) {
 	}
 	
 	var path string
+	driveID := req.DriveID
 	if req.ParentID != nil {
-		var parent models.Folder
-		if err := db.Where("id = ? AND user_id = ?", *req.ParentID, userID).First(&parent).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Parent folder not found"})
+		parent, err := findFolder(db, *req.ParentID, userID, authz.FilesWrite)
+		if err != nil {
+			respondAccessError(c, err, "Parent folder not found")
 			return
 		}
 		path = filepath.Join(parent.Path, req.Name)
+		driveID = parent.DriveID
 	} else {
+		if !authorizeSpace(c, db, driveID, authz.FilesWrite) {
+			return
+		}
 		path = req.Name
 	}
 	
 	folder := models.Folder{
 		Name:      req.Name,
 		ParentID:  req.ParentID,
+		DriveID:   driveID,
 		UserID:    userID,
 		IconType:  req.IconType,
 		IconColor: req.IconColor,
@@ -65,28 +82,29 @@ func CreateFolder(c *gin.Context) {
 		return
 	}
 	
-	physicalPath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), path)
+	physicalPath := folderStoragePath(&folder)
 	if err := os.MkdirAll(physicalPath, 0755); err != nil {
 		db.Delete(&folder)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create physical folder"})
 		return
 	}
 	
+	recordFolderChange(db, &folder, "create")
+	
 	c.JSON(http.StatusCreated, gin.H{"folder": folder})
 }
 
 func GetFolder(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	folderID := c.Param("id")
 	
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).
-		Preload("Subfolders").
-		Preload("Files").
-		First(&folder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	folder, ok := loadFolder(c, db, folderID, authz.FilesRead)
+	if !ok {
+		return
+	}
+	if err := db.Preload("Subfolders").Preload("Files").First(&folder, folder.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folder"})
 		return
 	}
 	
@@ -95,7 +113,6 @@ func GetFolder(c *gin.Context) {
 
 func GetFolderBreadcrumbs(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	folderID := c.Param("id")
 	
@@ -115,12 +132,19 @@ func GetFolderBreadcrumbs(c *gin.Context) {
 	}
 	
 	// Get the current folder and build path
-	var currentFolder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&currentFolder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	currentFolder, ok := loadFolder(c, db, folderID, authz.FilesRead)
+	if !ok {
 		return
 	}
 	
+	// Inside a team drive the trail starts at the drive instead of Home
+	if currentFolder.DriveID != nil {
+		var drive models.TeamDrive
+		if err := db.First(&drive, *currentFolder.DriveID).Error; err == nil {
+			breadcrumbs[0].Name = drive.Name
+		}
+	}
+	
 	// Build breadcrumbs by traversing up the parent chain
 	var pathFolders []models.Folder
 	current := currentFolder
@@ -133,7 +157,7 @@ func GetFolderBreadcrumbs(c *gin.Context) {
 		}
 		
 		var parent models.Folder
-		if err := db.Where("id = ? AND user_id = ?", *current.ParentID, userID).First(&parent).Error; err != nil {
+		if err := db.First(&parent, *current.ParentID).Error; err != nil {
 			break
 		}
 		current = parent
@@ -152,43 +176,21 @@ func GetFolderBreadcrumbs(c *gin.Context) {
 
 func UpdateFolder(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	folderID := c.Param("id")
 	
-	var req struct {
-		Name      string `json:"name"`
-		IconType  string `json:"icon_type"`
-		IconColor string `json:"icon_color"`
-	}
+	var req UpdateFolderRequest
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	folder, ok := loadFolder(c, db, folderID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
-	if req.Name != "" && req.Name != folder.Name {
-		oldPath := folder.Path
-		newPath := filepath.Join(filepath.Dir(folder.Path), req.Name)
-		
-		oldPhysicalPath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), oldPath)
-		newPhysicalPath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), newPath)
-		
-		if err := os.Rename(oldPhysicalPath, newPhysicalPath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename physical folder"})
-			return
-		}
-		
-		folder.Name = req.Name
-		folder.Path = newPath
-	}
-	
 	if req.IconType != "" {
 		folder.IconType = req.IconType
 	}
@@ -196,28 +198,70 @@ func UpdateFolder(c *gin.Context) {
 	if req.IconColor != "" {
 		folder.IconColor = req.IconColor
 	}
-	
-	if err := db.Save(&folder).Error; err != nil {
+
+	// A sibling with the new name would share its directory
+	if req.Name != "" && req.Name != folder.Name {
+		dest := destination{driveID: folder.DriveID}
+		if folder.ParentID != nil {
+			var parent models.Folder
+			if err := db.First(&parent, *folder.ParentID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder"})
+				return
+			}
+			dest.folder = &parent
+		}
+		if _, _, err := resolveFolderName(db, folder.UserID, dest, req.Name, folder.ID, conflictReject); err != nil {
+			respondTransferError(c, err, "Failed to update folder")
+			return
+		}
+	}
+
+	action := "update"
+	var undoRename func()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&folder).Error; err != nil {
+			return err
+		}
+		if req.Name == "" || req.Name == folder.Name {
+			return nil
+		}
+		
+		// Renaming moves the directory and every path stored below it
+		action = "rename"
+		root := storageRoot(folder.UserID, folder.DriveID)
+		oldPhysicalPath := filepath.Join(root, folder.Path)
+		newPath := filepath.Join(filepath.Dir(folder.Path), req.Name)
+		if err := relocateFolder(tx, &folder, folder.ParentID, req.Name, newPath); err != nil {
+			return err
+		}
+		var err error
+		undoRename, err = renameDir(oldPhysicalPath, filepath.Join(root, newPath))
+		return err
+	})
+	if err != nil {
+		if undoRename != nil {
+			undoRename()
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder"})
 		return
 	}
 	
+	recordFolderChange(db, &folder, action)
+	
 	c.JSON(http.StatusOK, gin.H{"folder": folder})
 }
 
 func DeleteFolder(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	folderID := c.Param("id")
 	
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	folder, ok := loadFolder(c, db, folderID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
-	physicalPath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), folder.Path)
+	physicalPath := folderStoragePath(&folder)
 	if err := os.RemoveAll(physicalPath); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete physical folder"})
 		return
@@ -228,6 +272,8 @@ func DeleteFolder(c *gin.Context) {
 		return
 	}
 	
+	recordFolderChange(db, &folder, "delete")
+	
 	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
 }
 
@@ -237,9 +283,8 @@ func CreateZipArchive(c *gin.Context) {
 	
 	folderID := c.Param("id")
 	
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	folder, ok := loadFolder(c, db, folderID, authz.FilesRead)
+	if !ok {
 		return
 	}
 	
@@ -258,36 +303,7 @@ func CreateZipArchive(c *gin.Context) {
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 	
-	sourcePath := filepath.Join(os.Getenv("ROOT_DIRECTORY"), "root", fmt.Sprintf("%d", userID), folder.Path)
-	
-	err = filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
-		relPath, err := filepath.Rel(sourcePath, path)
-		if err != nil {
-			return err
-		}
-		
-		if info.IsDir() {
-			return nil
-		}
-		
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-		
-		writer, err := zipWriter.Create(relPath)
-		if err != nil {
-			return err
-		}
-		
-		_, err = io.Copy(writer, file)
-		return err
-	})
+	err = addFolderToZip(db, zipWriter, folder, "")
 	
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create zip archive"})
@@ -300,4 +316,45 @@ func CreateZipArchive(c *gin.Context) {
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", zipFileName))
 	c.Header("Content-Type", "application/zip")
 	c.File(zipPath)
-}
\ No newline at end of file
+}
+// addFolderToZip writes a folder tree to a zip under prefix. It follows the
+// database tree because file content is not stored inside folder directories.
+func addFolderToZip(db *gorm.DB, zipWriter *zip.Writer, folder models.Folder, prefix string) error {
+	folders, files, err := loadFolderTree(db, folder)
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[uint]string, len(folders))
+	for _, f := range folders {
+		rel := strings.TrimPrefix(strings.TrimPrefix(f.Path, folder.Path), string(filepath.Separator))
+		dirs[f.ID] = filepath.ToSlash(filepath.Join(prefix, rel))
+		if dirs[f.ID] != "" {
+			if _, err := zipWriter.Create(dirs[f.ID] + "/"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range files {
+		if err := addFileToZip(zipWriter, f.FilePath, path.Join(dirs[*f.FolderID], f.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addFileToZip(zipWriter *zip.Writer, src, name string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateGroupRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type AddGroupMemberRequest struct {
+	UserID  uint `json:"user_id" binding:"required"`
+	IsAdmin bool `json:"is_admin"`
+}
+
+type UpdateGroupMemberRequest struct {
+	IsAdmin bool `json:"is_admin"`
+}
+
+// CreateGroup creates a group with the current user as its first admin
+func CreateGroup(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	var req CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Group
+	if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+		return
+	}
+
+	group := models.Group{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GroupMember{GroupID: group.ID, UserID: userID, IsAdmin: true}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+
+	db.Preload("Members.User").First(&group, group.ID)
+	c.JSON(http.StatusCreated, gin.H{"group": group})
+}
+
+// ListGroups returns the groups the user belongs to; admins see every group
+func ListGroups(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	query := db.Preload("Members.User").Order("name ASC")
+	if !authz.UserHas(db, userID, authz.AdminGroupsManage) {
+		query = query.Where("id IN (?)", db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID))
+	}
+
+	var groups []models.Group
+	if err := query.Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+// GetGroup returns a group with its members and team drives
+func GetGroup(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	group, ok := loadGroup(c, db, false)
+	if !ok {
+		return
+	}
+
+	var drives []models.TeamDrive
+	if err := db.Where("group_id = ?", group.ID).Order("name ASC").Find(&drives).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team drives"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group": group, "drives": drives})
+}
+
+// UpdateGroup renames a group or changes its description
+func UpdateGroup(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, ok := loadGroup(c, db, true)
+	if !ok {
+		return
+	}
+
+	if req.Name != "" && req.Name != group.Name {
+		var existing models.Group
+		if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+			return
+		}
+		group.Name = req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if err := db.Omit("Members").Save(&group).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group": group})
+}
+
+// DeleteGroup deletes a group that no longer owns any team drive
+func DeleteGroup(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	group, ok := loadGroup(c, db, true)
+	if !ok {
+		return
+	}
+
+	var drives int64
+	db.Model(&models.TeamDrive{}).Where("group_id = ?", group.ID).Count(&drives)
+	if drives > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delete the group's team drives first"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, group.ID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// AddGroupMember adds a user to a group
+func AddGroupMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req AddGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, ok := loadGroup(c, db, true)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, req.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var existing models.GroupMember
+	if err := db.Where("group_id = ? AND user_id = ?", group.ID, user.ID).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this group"})
+		return
+	}
+
+	member := models.GroupMember{GroupID: group.ID, UserID: user.ID, IsAdmin: req.IsAdmin}
+	if err := db.Create(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+	member.User = user
+
+	c.JSON(http.StatusCreated, gin.H{"member": member})
+}
+
+// UpdateGroupMember grants or revokes group admin rights
+func UpdateGroupMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var req UpdateGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, ok := loadGroup(c, db, true)
+	if !ok {
+		return
+	}
+
+	member, ok := findGroupMember(c, db, group.ID)
+	if !ok {
+		return
+	}
+
+	if member.IsAdmin && !req.IsAdmin && isLastGroupAdmin(db, member) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group needs at least one admin"})
+		return
+	}
+
+	member.IsAdmin = req.IsAdmin
+	if err := db.Omit("User").Save(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+// RemoveGroupMember removes a user from a group. Members may always leave.
+func RemoveGroupMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	leaving := c.Param("user_id") == strconv.FormatUint(uint64(userID), 10)
+	group, ok := loadGroup(c, db, !leaving)
+	if !ok {
+		return
+	}
+
+	member, ok := findGroupMember(c, db, group.ID)
+	if !ok {
+		return
+	}
+
+	if member.IsAdmin && isLastGroupAdmin(db, member) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group needs at least one admin"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		driveIDs := tx.Model(&models.TeamDrive{}).Select("id").Where("group_id = ?", group.ID)
+		if err := tx.Where("user_id = ? AND drive_id IN (?)", member.UserID, driveIDs).Delete(&models.TeamDriveMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&member).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// loadGroup loads the group named by the :id parameter. Non-members get 404;
+// members who are not group admins get 403 when admin rights are required.
+func loadGroup(c *gin.Context, db *gorm.DB, requireAdmin bool) (models.Group, bool) {
+	userID := c.MustGet("user_id").(uint)
+
+	var group models.Group
+	if err := db.Preload("Members.User").Where("id = ?", c.Param("id")).First(&group).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	isAdmin, isMember := authz.GroupMembership(db, group.ID, userID)
+	if !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+	if requireAdmin && !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return group, false
+	}
+	return group, true
+}
+
+// findGroupMember loads the membership named by the :user_id parameter
+func findGroupMember(c *gin.Context, db *gorm.DB, groupID uint) (models.GroupMember, bool) {
+	var member models.GroupMember
+	if err := db.Preload("User").Where("group_id = ? AND user_id = ?", groupID, c.Param("user_id")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return member, false
+	}
+	return member, true
+}
+
+// isLastGroupAdmin reports whether no other admin would remain in the group
+func isLastGroupAdmin(db *gorm.DB, member models.GroupMember) bool {
+	var others int64
+	db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND is_admin = ? AND id != ?", member.GroupID, true, member.ID).
+		Count(&others)
+	return others == 0
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/config"
+	"a-drive-backend/health"
+	"a-drive-backend/logging"
+	"a-drive-backend/maintenance"
+)
+
+// Livez answers liveness probes. It only shows that the process serves
+// requests, so that orchestrators restart it when it hangs but not when a
+// dependency is down.
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.OK})
+}
+
+// Readyz answers readiness probes. The server is ready when the database
+// answers and the storage directory is writable with enough free space, and
+// stops being ready once it is shutting down. Maintenance mode is reported
+// but does not make it unready, since reads still work.
+func Readyz(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	cfg := config.Load()
+	log := logging.FromContext(c.Request.Context())
+
+	checks := map[string]health.Check{}
+	run := func(name string, check health.Check, err error) {
+		checks[name] = check
+		if err != nil || check.Status == health.Fail {
+			log.Warn("Readiness check failed", "check", name, "message", check.Error, "error", err)
+		}
+	}
+	check, err := health.Database(c.Request.Context(), db)
+	run("database", check, err)
+	check, err = health.Storage(cfg.RootDirectory)
+	run("storage", check, err)
+	check, err = health.DiskSpace(cfg.RootDirectory, cfg.ReadyMinFreeSpace)
+	run("disk", check, err)
+
+	status, code := "ready", http.StatusOK
+	for _, result := range checks {
+		if result.Status == health.Fail {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+	if health.ShuttingDown() {
+		status, code = "shutting_down", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":      status,
+		"maintenance": maintenance.Enabled(),
+		"checks":      checks,
+	})
+}
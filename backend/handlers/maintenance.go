@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/logging"
+	"a-drive-backend/maintenance"
+)
+
+// MaintenanceRequest turns maintenance mode on or off. Message is shown to
+// clients whose changes are rejected.
+type MaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message" binding:"max=500"`
+}
+
+// GetMaintenance returns the maintenance mode
+func GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"maintenance": maintenance.Get()})
+}
+
+// SetMaintenance turns maintenance mode on or off. It lasts until it is
+// turned off or the server restarts, which reads MAINTENANCE_MODE again.
+func SetMaintenance(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	state := maintenance.Set(*req.Enabled, req.Message)
+	logging.FromContext(c.Request.Context()).Warn("Maintenance mode changed", "enabled", state.Enabled)
+
+	message := "Maintenance mode disabled"
+	if state.Enabled {
+		message = "Maintenance mode enabled"
+	}
+	c.JSON(http.StatusOK, gin.H{"maintenance": state, "message": message})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/config"
+	"a-drive-backend/logging"
+	"a-drive-backend/metrics"
+)
+
+// GetMetrics writes the server's metrics in the Prometheus text format. When
+// METRICS_TOKEN is set, scrapers must send it as a bearer token.
+func GetMetrics(c *gin.Context) {
+	cfg := config.Load()
+	if !cfg.MetricsEnabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Metrics are disabled"})
+		return
+	}
+	if cfg.MetricsToken != "" {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.MetricsToken)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := metrics.Default.Write(c.Writer); err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to write metrics", "error", err)
+	}
+}
@@ -0,0 +1,783 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+// Name conflict policies for moves, copies and uploads. Without one, or with
+// reject, a conflict fails.
+const (
+	conflictReject    = "reject"
+	conflictKeepBoth  = "keep_both"
+	conflictOverwrite = "overwrite"
+	conflictSkip      = "skip"
+)
+
+var (
+	errNameConflict = errors.New("an item with this name already exists in the destination")
+	errCrossSpace   = errors.New("items cannot be moved between spaces")
+	errIntoItself   = errors.New("cannot move a folder into itself")
+	errReplaceOwn   = errors.New("cannot replace a folder that contains the item")
+)
+
+// TransferRequest names the destination of a move or clone. A null
+// target_folder_id means the root of the personal space, or of the team
+// drive drive_id.
+type TransferRequest struct {
+	TargetFolderID *uint  `json:"target_folder_id"`
+	DriveID        *uint  `json:"drive_id"`
+	OnConflict     string `json:"on_conflict" binding:"omitempty,oneof=reject keep_both overwrite skip"`
+}
+
+// destination is a folder, or the root of a space when folder is nil
+type destination struct {
+	folder  *models.Folder
+	driveID *uint
+}
+
+func (d destination) folderID() *uint {
+	if d.folder == nil {
+		return nil
+	}
+	return &d.folder.ID
+}
+
+func (d destination) path(name string) string {
+	if d.folder == nil {
+		return name
+	}
+	return filepath.Join(d.folder.Path, name)
+}
+
+// MoveFile moves a file into another folder of the same space
+func MoveFile(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	req, dest, ok := bindTransfer(c, db)
+	if !ok {
+		return
+	}
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+
+	moved, skipped, err := moveFileTo(db, userID, file, dest, req.OnConflict)
+	if err != nil {
+		respondTransferError(c, err, "Failed to move file")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"file": moved, "skipped": skipped})
+}
+
+// CopyFile copies the current content of a file into a folder of any space
+// the user can write to. The copy belongs to the user and has no versions.
+func CopyFile(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	req, dest, ok := bindTransfer(c, db)
+	if !ok {
+		return
+	}
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	copied, skipped, err := copyFileTo(db, userID, file, dest, req.OnConflict)
+	if err != nil {
+		respondTransferError(c, err, "Failed to copy file")
+		return
+	}
+
+	status := http.StatusCreated
+	if skipped {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"file": copied, "skipped": skipped})
+}
+
+// MoveFolder moves a folder with everything below it into another folder of
+// the same space
+func MoveFolder(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	req, dest, ok := bindTransfer(c, db)
+	if !ok {
+		return
+	}
+
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+
+	moved, skipped, err := moveFolderTo(db, userID, folder, dest, req.OnConflict)
+	if err != nil {
+		respondTransferError(c, err, "Failed to move folder")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"folder": moved, "skipped": skipped})
+}
+
+// CopyFolder copies a folder tree into a folder of any space the user can
+// write to
+func CopyFolder(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	req, dest, ok := bindTransfer(c, db)
+	if !ok {
+		return
+	}
+
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	copied, skipped, err := copyFolderTo(db, userID, folder, dest, req.OnConflict)
+	if err != nil {
+		respondTransferError(c, err, "Failed to copy folder")
+		return
+	}
+
+	status := http.StatusCreated
+	if skipped {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"folder": copied, "skipped": skipped})
+}
+
+// bindTransfer reads a TransferRequest and resolves its destination
+func bindTransfer(c *gin.Context, db *gorm.DB) (TransferRequest, destination, bool) {
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, destination{}, false
+	}
+	dest, ok := transferDestination(c, db, req)
+	return req, dest, ok
+}
+
+// transferDestination resolves the destination of a TransferRequest, which
+// the user must be allowed to write to
+func transferDestination(c *gin.Context, db *gorm.DB, req TransferRequest) (destination, bool) {
+	if req.TargetFolderID != nil {
+		target, err := findFolder(db, *req.TargetFolderID, c.MustGet("user_id").(uint), authz.FilesWrite)
+		if err != nil {
+			respondAccessError(c, err, "Target folder not found")
+			return destination{}, false
+		}
+		return destination{folder: &target, driveID: target.DriveID}, true
+	}
+
+	if !authorizeSpace(c, db, req.DriveID, authz.FilesWrite) {
+		return destination{}, false
+	}
+	return destination{driveID: req.DriveID}, true
+}
+
+func respondTransferError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errNameConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "An item with this name already exists in the destination"})
+	case errors.Is(err, errCrossSpace):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Items cannot be moved between spaces; copy them instead"})
+	case errors.Is(err, errIntoItself):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot move a folder into itself"})
+	case errors.Is(err, errReplaceOwn):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot replace a folder that contains the item"})
+	case errors.Is(err, errQuotaExceeded):
+		respondQuotaError(c, err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// moveFileTo moves a file into dest, which must be in the file's space
+func moveFileTo(db *gorm.DB, userID uint, file models.File, dest destination, policy string) (models.File, bool, error) {
+	if !sameDrive(file.DriveID, dest.driveID) {
+		return file, false, errCrossSpace
+	}
+
+	name, existing, err := resolveFileName(db, userID, dest, file.Name, file.ID, policy)
+	if err != nil || (existing != nil && policy == conflictSkip) {
+		return fileOrExisting(file, existing), existing != nil, err
+	}
+
+	var garbage []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if existing != nil {
+			paths, err := deleteFileRecord(tx, *existing)
+			if err != nil {
+				return err
+			}
+			garbage = paths
+		}
+		file.Name = name
+		file.FolderID = dest.folderID()
+		return tx.Model(&file).Select("name", "folder_id").Updates(&file).Error
+	})
+	if err != nil {
+		return file, false, err
+	}
+
+	removePaths(garbage)
+	if existing != nil {
+		recordFileChange(db, existing, "delete")
+	}
+	recordFileChange(db, &file, "move")
+	return file, false, nil
+}
+
+// copyFileTo copies the current content of a file into dest
+func copyFileTo(db *gorm.DB, userID uint, file models.File, dest destination, policy string) (models.File, bool, error) {
+	name, existing, err := resolveFileName(db, userID, dest, file.Name, 0, policy)
+	if err != nil || (existing != nil && policy == conflictSkip) {
+		return fileOrExisting(file, existing), existing != nil, err
+	}
+	if existing != nil && existing.ID == file.ID {
+		// Overwriting a file with itself changes nothing
+		return file, true, nil
+	}
+
+	replacedSize := int64(0)
+	if existing != nil {
+		replacedSize = existing.Size
+	}
+	if err := checkDriveQuota(db, dest.driveID, file.Size-replacedSize); err != nil {
+		return file, false, err
+	}
+
+	root := storageRoot(userID, dest.driveID)
+	blob, err := copyBlob(file.FilePath, root, userID, name)
+	if err != nil {
+		return file, false, err
+	}
+
+	copied := models.File{
+		Name:         name,
+		OriginalName: file.OriginalName,
+		FolderID:     dest.folderID(),
+		DriveID:      dest.driveID,
+		UserID:       userID,
+		FilePath:     blob,
+		Size:         file.Size,
+		MimeType:     file.MimeType,
+		Checksum:     file.Checksum,
+	}
+
+	var garbage []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if existing != nil {
+			paths, err := deleteFileRecord(tx, *existing)
+			if err != nil {
+				return err
+			}
+			garbage = paths
+		}
+		return tx.Create(&copied).Error
+	})
+	if err != nil {
+		os.Remove(blob)
+		return file, false, err
+	}
+
+	removePaths(garbage)
+	if existing != nil {
+		recordFileChange(db, existing, "delete")
+	}
+	recordFileChange(db, &copied, "create")
+	return copied, false, nil
+}
+
+// moveFolderTo moves a folder tree into dest, which must be in the folder's
+// space and not below the folder itself
+func moveFolderTo(db *gorm.DB, userID uint, folder models.Folder, dest destination, policy string) (models.Folder, bool, error) {
+	if !sameDrive(folder.DriveID, dest.driveID) {
+		return folder, false, errCrossSpace
+	}
+	if dest.folder != nil && isDescendantOf(db, dest.folder.ID, folder.ID) {
+		return folder, false, errIntoItself
+	}
+
+	name, existing, err := resolveFolderName(db, userID, dest, folder.Name, folder.ID, policy)
+	if err != nil || (existing != nil && policy == conflictSkip) {
+		return folderOrExisting(folder, existing), existing != nil, err
+	}
+	if existing != nil && isDescendantOf(db, folder.ID, existing.ID) {
+		return folder, false, errReplaceOwn
+	}
+
+	root := storageRoot(folder.UserID, folder.DriveID)
+	oldDir := filepath.Join(root, folder.Path)
+	newDir := filepath.Join(root, dest.path(name))
+
+	var garbage []string
+	var trash string
+	var undoRename func()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if existing != nil {
+			paths, err := deleteFolderTree(tx, *existing)
+			if err != nil {
+				return err
+			}
+			garbage = paths
+			if trash, err = moveAside(filepath.Join(root, existing.Path)); err != nil {
+				return err
+			}
+		}
+
+		if err := relocateFolder(tx, &folder, dest.folderID(), name, dest.path(name)); err != nil {
+			return err
+		}
+		var err error
+		undoRename, err = renameDir(oldDir, newDir)
+		return err
+	})
+	if err != nil {
+		// The folder goes back first, it may sit where the replaced one was
+		if undoRename != nil {
+			undoRename()
+		}
+		if trash != "" {
+			restoreAside(trash)
+		}
+		return folder, false, err
+	}
+
+	removePaths(garbage)
+	if trash != "" {
+		os.RemoveAll(trash)
+	}
+	if existing != nil {
+		recordFolderChange(db, existing, "delete")
+	}
+	recordFolderChange(db, &folder, "move")
+	return folder, false, nil
+}
+
+// copyFolderTo copies a folder tree into dest. Everything copied belongs to the user.
+func copyFolderTo(db *gorm.DB, userID uint, folder models.Folder, dest destination, policy string) (models.Folder, bool, error) {
+	name, existing, err := resolveFolderName(db, userID, dest, folder.Name, 0, policy)
+	if err != nil || (existing != nil && policy == conflictSkip) {
+		return folderOrExisting(folder, existing), existing != nil, err
+	}
+	if existing != nil && existing.ID == folder.ID {
+		// Overwriting a folder with itself changes nothing, as for files
+		return folder, true, nil
+	}
+	if existing != nil && isDescendantOf(db, folder.ID, existing.ID) {
+		return folder, false, errReplaceOwn
+	}
+
+	// Snapshot the tree first so copying a folder into itself terminates
+	folders, files, err := loadFolderTree(db, folder)
+	if err != nil {
+		return folder, false, err
+	}
+
+	var size, replacedSize int64
+	for _, f := range files {
+		size += f.Size
+	}
+	if existing != nil {
+		_, replacedFiles, err := loadFolderTree(db, *existing)
+		if err != nil {
+			return folder, false, err
+		}
+		for _, f := range replacedFiles {
+			replacedSize += f.Size
+		}
+	}
+	if err := checkDriveQuota(db, dest.driveID, size-replacedSize); err != nil {
+		return folder, false, err
+	}
+
+	root := storageRoot(userID, dest.driveID)
+	var created []string // blobs and directories to remove if the copy fails
+	var garbage []string
+	var trash string
+	var copies []models.Folder
+	var copiedFiles []models.File
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if existing != nil {
+			paths, err := deleteFolderTree(tx, *existing)
+			if err != nil {
+				return err
+			}
+			garbage = paths
+			if trash, err = moveAside(filepath.Join(root, existing.Path)); err != nil {
+				return err
+			}
+		}
+
+		// Folders come parents first, so each parent's copy exists before its children
+		copyIDs := make(map[uint]uint)
+		for _, f := range folders {
+			clone := models.Folder{
+				Name:      f.Name,
+				DriveID:   dest.driveID,
+				UserID:    userID,
+				IconType:  f.IconType,
+				IconColor: f.IconColor,
+			}
+			if f.ID == folder.ID {
+				clone.Name = name
+				clone.ParentID = dest.folderID()
+				clone.Path = dest.path(name)
+			} else {
+				parentID := copyIDs[*f.ParentID]
+				clone.ParentID = &parentID
+				clone.Path = filepath.Join(pathOf(copies, parentID), f.Name)
+			}
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+			dir := filepath.Join(root, clone.Path)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			if f.ID == folder.ID {
+				created = append(created, dir)
+			}
+			copyIDs[f.ID] = clone.ID
+			copies = append(copies, clone)
+		}
+
+		for _, f := range files {
+			blob, err := copyBlob(f.FilePath, root, userID, f.Name)
+			if err != nil {
+				return err
+			}
+			created = append(created, blob)
+
+			folderID := copyIDs[*f.FolderID]
+			clone := models.File{
+				Name:         f.Name,
+				OriginalName: f.OriginalName,
+				FolderID:     &folderID,
+				DriveID:      dest.driveID,
+				UserID:       userID,
+				FilePath:     blob,
+				Size:         f.Size,
+				MimeType:     f.MimeType,
+				Checksum:     f.Checksum,
+			}
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+			copiedFiles = append(copiedFiles, clone)
+		}
+		return nil
+	})
+	if err != nil {
+		removePaths(created)
+		if trash != "" {
+			restoreAside(trash)
+		}
+		return folder, false, err
+	}
+
+	removePaths(garbage)
+	if trash != "" {
+		os.RemoveAll(trash)
+	}
+	if existing != nil {
+		recordFolderChange(db, existing, "delete")
+	}
+	for i := range copies {
+		recordFolderChange(db, &copies[i], "create")
+	}
+	for i := range copiedFiles {
+		recordFileChange(db, &copiedFiles[i], "create")
+	}
+	return copies[0], false, nil
+}
+
+// resolveFileName applies the conflict policy to a file name in dest. It
+// returns the name to use and the conflicting file, if any. exceptID excludes
+// the file being moved.
+func resolveFileName(db *gorm.DB, userID uint, dest destination, name string, exceptID uint, policy string) (string, *models.File, error) {
+	taken := func(candidate string) *models.File {
+		var existing models.File
+		query := personalOrDrive(db, userID, dest.driveID).Where("name = ? AND id != ?", candidate, exceptID)
+		query = whereParent(query, "folder_id", dest.folderID())
+		if err := query.First(&existing).Error; err != nil {
+			return nil
+		}
+		return &existing
+	}
+
+	existing := taken(name)
+	if existing == nil {
+		return name, nil, nil
+	}
+	switch policy {
+	case conflictKeepBoth:
+		return uniqueName(name, true, func(n string) bool { return taken(n) != nil }), nil, nil
+	case conflictOverwrite, conflictSkip:
+		return name, existing, nil
+	}
+	return name, existing, errNameConflict
+}
+
+// resolveFolderName is resolveFileName for folders
+func resolveFolderName(db *gorm.DB, userID uint, dest destination, name string, exceptID uint, policy string) (string, *models.Folder, error) {
+	taken := func(candidate string) *models.Folder {
+		var existing models.Folder
+		query := personalOrDrive(db, userID, dest.driveID).Where("name = ? AND id != ?", candidate, exceptID)
+		query = whereParent(query, "parent_id", dest.folderID())
+		if err := query.First(&existing).Error; err != nil {
+			return nil
+		}
+		return &existing
+	}
+
+	existing := taken(name)
+	if existing == nil {
+		return name, nil, nil
+	}
+	switch policy {
+	case conflictKeepBoth:
+		return uniqueName(name, false, func(n string) bool { return taken(n) != nil }), nil, nil
+	case conflictOverwrite, conflictSkip:
+		return name, existing, nil
+	}
+	return name, existing, errNameConflict
+}
+
+func whereParent(query *gorm.DB, column string, parentID *uint) *gorm.DB {
+	if parentID == nil {
+		return query.Where(column + " IS NULL")
+	}
+	return query.Where(column+" = ?", *parentID)
+}
+
+// uniqueName appends " (1)", " (2)", ... until the name is free. File names
+// keep their extension last.
+func uniqueName(name string, keepExt bool, taken func(string) bool) string {
+	base, ext := name, ""
+	if keepExt {
+		ext = filepath.Ext(name)
+		base = strings.TrimSuffix(name, ext)
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// relocateFolder gives a folder a new parent and name and rewrites the stored
+// path of every folder below it. It does not touch the disk.
+func relocateFolder(tx *gorm.DB, folder *models.Folder, parentID *uint, name, path string) error {
+	descendants, _, err := loadFolderTree(tx, *folder)
+	if err != nil {
+		return err
+	}
+
+	oldPrefix := folder.Path + string(filepath.Separator)
+	newPrefix := path + string(filepath.Separator)
+	for _, d := range descendants[1:] {
+		if err := tx.Model(&models.Folder{}).Where("id = ?", d.ID).
+			Update("path", rebasePath(d.Path, oldPrefix, newPrefix)).Error; err != nil {
+			return err
+		}
+	}
+
+	folder.ParentID = parentID
+	folder.Name = name
+	folder.Path = path
+	return tx.Model(folder).Select("parent_id", "name", "path").Updates(folder).Error
+}
+
+// loadFolderTree returns a folder followed by all folders below it, parents
+// before children, and all files in the tree
+func loadFolderTree(db *gorm.DB, root models.Folder) ([]models.Folder, []models.File, error) {
+	folders := []models.Folder{root}
+	for i := 0; i < len(folders); i++ {
+		var children []models.Folder
+		if err := db.Where("parent_id = ?", folders[i].ID).Order("id").Find(&children).Error; err != nil {
+			return nil, nil, err
+		}
+		folders = append(folders, children...)
+	}
+
+	ids := make([]uint, len(folders))
+	for i, f := range folders {
+		ids[i] = f.ID
+	}
+	var files []models.File
+	if err := db.Where("folder_id IN ?", ids).Order("id").Find(&files).Error; err != nil {
+		return nil, nil, err
+	}
+	return folders, files, nil
+}
+
+// deleteFileRecord deletes a file row with its versions and returns the
+// blobs to remove once the transaction has committed
+func deleteFileRecord(tx *gorm.DB, file models.File) ([]string, error) {
+	var versions []models.FileVersion
+	if err := tx.Where("file_id = ?", file.ID).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	paths := []string{file.FilePath}
+	for _, v := range versions {
+		if v.FilePath != "" && v.FilePath != file.FilePath {
+			paths = append(paths, v.FilePath)
+		}
+	}
+
+	if err := tx.Where("file_id = ?", file.ID).Delete(&models.FileVersion{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Delete(&file).Error; err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// deleteFolderTree deletes a folder with everything below it and returns the
+// blobs to remove once the transaction has committed
+func deleteFolderTree(tx *gorm.DB, folder models.Folder) ([]string, error) {
+	folders, files, err := loadFolderTree(tx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range files {
+		filePaths, err := deleteFileRecord(tx, f)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, filePaths...)
+	}
+	for _, f := range folders {
+		if err := tx.Delete(&f).Error; err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// copyBlob copies a stored file into dir under a new blob key
+func copyBlob(src, dir string, userID uint, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path, err := newBlobPath(dir, userID, name)
+	if err != nil {
+		return "", err
+	}
+	if err := copyFile(src, path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// renameDir moves a folder's directory. Folders created before their
+// directory existed simply get a new one. It runs inside the transaction
+// that moves the folder's rows; call the returned undo if that transaction
+// fails, so that disk and database stay in step.
+func renameDir(oldDir, newDir string) (undo func(), err error) {
+	if oldDir == newDir {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(newDir), 0755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(oldDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(newDir, 0755); err != nil {
+			return nil, err
+		}
+		return func() { os.Remove(newDir) }, nil
+	}
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return nil, err
+	}
+	return func() { os.Rename(newDir, oldDir) }, nil
+}
+
+// moveAside renames a directory that is about to be replaced so that it can
+// be restored if the operation fails
+func moveAside(dir string) (string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", nil
+	}
+	trash := fmt.Sprintf("%s.replaced-%d", dir, time.Now().UnixNano())
+	if err := os.Rename(dir, trash); err != nil {
+		return "", err
+	}
+	return trash, nil
+}
+
+func restoreAside(trash string) {
+	dir := trash[:strings.LastIndex(trash, ".replaced-")]
+	if err := os.Rename(trash, dir); err != nil {
+		slog.Error("Failed to restore directory", "path", dir, "error", err)
+	}
+}
+
+func removePaths(paths []string) {
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			slog.Error("Failed to remove path", "path", path, "error", err)
+		}
+	}
+}
+
+func sameDrive(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func pathOf(folders []models.Folder, id uint) string {
+	for _, f := range folders {
+		if f.ID == id {
+			return f.Path
+		}
+	}
+	return ""
+}
+
+func fileOrExisting(file models.File, existing *models.File) models.File {
+	if existing != nil {
+		return *existing
+	}
+	return file
+}
+
+func folderOrExisting(folder models.Folder, existing *models.Folder) models.Folder {
+	if existing != nil {
+		return *existing
+	}
+	return folder
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/config"
+	"a-drive-backend/models"
+	"a-drive-backend/preview"
+)
+
+// Preview formats
+const (
+	previewRendered = "rendered"
+	previewRaw      = "raw"
+)
+
+// PreviewFile shows a file inline in the browser
+func PreviewFile(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	serveFilePreview(c, &file, jsonError)
+}
+
+// jsonError writes an API error response
+func jsonError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"error": message})
+}
+
+// serveFilePreview writes a file for inline viewing. Markdown and source code
+// are rendered as HTML pages unless the raw format is asked for; everything
+// else is served as it is with a Content-Security-Policy that keeps scripts
+// in it from running on our origin. Errors are written with respondError.
+func serveFilePreview(c *gin.Context, file *models.File, respondError func(c *gin.Context, status int, message string)) {
+	format := c.DefaultQuery("format", previewRendered)
+	if format != previewRendered && format != previewRaw {
+		respondError(c, http.StatusBadRequest, "format must be rendered or raw")
+		return
+	}
+
+	kind := preview.Kind(file.Name, file.MimeType)
+	if kind == "" {
+		respondError(c, http.StatusUnsupportedMediaType, "This file type cannot be previewed")
+		return
+	}
+
+	if _, err := os.Stat(file.FilePath); err != nil {
+		respondError(c, http.StatusNotFound, "File content not found")
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": file.Name}))
+
+	// Files too large to render are shown as plain text instead
+	if format == previewRendered && (kind == preview.Markdown || kind == preview.Code) && file.Size <= config.Load().PreviewMaxRenderSize {
+		source, err := readPreviewText(file.FilePath, config.Load().PreviewMaxRenderSize)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to read file")
+			return
+		}
+
+		page := preview.RenderCode(file.Name, source)
+		if kind == preview.Markdown {
+			page = preview.Page(file.Name, preview.RenderMarkdown(source))
+		}
+		c.Header("Content-Security-Policy", preview.PageCSP)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+		return
+	}
+
+	if kind == preview.Text || kind == preview.Markdown || kind == preview.Code || format == previewRaw && preview.IsText(kind) {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		c.Header("Content-Type", preview.ContentType(file.Name, file.MimeType))
+	}
+	c.Header("Content-Security-Policy", preview.CSP(kind))
+	c.File(file.FilePath)
+}
+
+// readPreviewText reads up to limit bytes of a text file, replacing invalid
+// UTF-8
+func readPreviewText(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return "", err
+	}
+	return strings.ToValidUTF8(string(data), "�"), nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
 	"a-drive-backend/models"
 )
 
@@ -84,10 +85,8 @@ func TrackFileAccess(c *gin.Context) {
 		return
 	}
 
-	// Check if file exists and belongs to user
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	// Check if file exists and the user can see it
+	if _, ok := loadFile(c, db, fileID, authz.FilesRead); !ok {
 		return
 	}
 
@@ -132,10 +131,8 @@ func TrackFolderAccess(c *gin.Context) {
 		return
 	}
 
-	// Check if folder exists and belongs to user
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	// Check if folder exists and the user can see it
+	if _, ok := loadFolder(c, db, folderID, authz.FilesRead); !ok {
 		return
 	}
 
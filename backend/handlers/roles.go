@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+)
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// ListPermissions returns every permission that can be put into a role
+func ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": authz.Definitions})
+}
+
+// ListRoles returns every role with the number of users holding it
+func ListRoles(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var roles []models.Role
+	if err := db.Order("name ASC").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	for i := range roles {
+		db.Model(&models.User{}).Where("role = ?", roles[i].Name).Count(&roles[i].UserCount)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// CreateRole defines a new role
+func CreateRole(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser := c.MustGet("user").(models.User)
+
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Role
+	if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A role with this name already exists"})
+		return
+	}
+
+	role := models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: models.StringList{},
+	}
+	if !setRolePermissions(c, db, &currentUser, &role, req.Permissions) {
+		return
+	}
+
+	if err := db.Create(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"role": role})
+}
+
+// UpdateRole changes the description or the permissions of a role. The
+// permissions of the built-in admin role cannot be changed.
+func UpdateRole(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser := c.MustGet("user").(models.User)
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var role models.Role
+	if err := db.Where("id = ?", c.Param("id")).First(&role).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	if req.Permissions != nil {
+		if role.Name == authz.RoleAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The permissions of the admin role cannot be changed"})
+			return
+		}
+		// Editing a role must not hand out more than the editor holds either
+		if !authz.CanGrant(db, &currentUser, role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot edit a role with permissions you do not have"})
+			return
+		}
+		if !setRolePermissions(c, db, &currentUser, &role, req.Permissions) {
+			return
+		}
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	if err := db.Save(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
+
+// DeleteRole deletes a role nobody holds. Built-in roles cannot be deleted.
+func DeleteRole(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var role models.Role
+	if err := db.Where("id = ?", c.Param("id")).First(&role).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	if role.BuiltIn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var holders int64
+	db.Model(&models.User{}).Where("role = ?", role.Name).Count(&holders)
+	if holders > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role is still assigned to users"})
+		return
+	}
+
+	if err := db.Delete(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// setRolePermissions validates permission names and stores them on the role,
+// writing the error response itself when they are unknown or not grantable
+func setRolePermissions(c *gin.Context, db *gorm.DB, user *models.User, role *models.Role, permissions []string) bool {
+	list := models.StringList{}
+	seen := make(map[string]bool)
+	for _, p := range permissions {
+		if !authz.Valid(p) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission: " + p})
+			return false
+		}
+		if !seen[p] {
+			seen[p] = true
+			list = append(list, p)
+		}
+	}
+
+	candidate := *role
+	candidate.Permissions = list
+	if !authz.CanGrant(db, user, candidate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot grant permissions you do not have"})
+		return false
+	}
+
+	role.Permissions = list
+	return true
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/database"
 	"a-drive-backend/models"
 )
 
@@ -17,7 +19,8 @@ type SearchResult struct {
 	Total   int             `json:"total"`
 }
 
-// processWildcardQuery converts wildcard patterns to SQL LIKE patterns
+// processWildcardQuery converts wildcard patterns to SQL LIKE patterns for
+// database.ILike
 func processWildcardQuery(query string) (string, bool) {
 	// Check if query contains wildcard characters
 	if !strings.Contains(query, "*") && !strings.Contains(query, "?") {
@@ -25,8 +28,7 @@ func processWildcardQuery(query string) (string, bool) {
 	}
 	
 	// Escape existing SQL special characters
-	escaped := strings.ReplaceAll(query, "%", "\\%")
-	escaped = strings.ReplaceAll(escaped, "_", "\\_")
+	escaped := database.EscapeLike(query)
 	
 	// Convert wildcard patterns to SQL LIKE patterns
 	// * matches any sequence of characters (including empty)
@@ -73,19 +75,35 @@ func SearchFiles(c *gin.Context) {
 	if isWildcard {
 		searchPattern = processedQuery
 	} else {
-		searchPattern = "%" + query + "%"
+		searchPattern = "%" + database.EscapeLike(query) + "%"
 	}
 	
 	
+	// Search the user's own files and their team drives, or a single drive
+	driveID, err := parseDriveID(c.Query("drive_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+	if !authorizeSpace(c, db, driveID, authz.FilesRead) {
+		return
+	}
+	scope := func(query *gorm.DB) *gorm.DB {
+		if driveID != nil {
+			return query.Where("drive_id = ?", *driveID)
+		}
+		return readableItems(db, query, userID)
+	}
+	
 	// Search files
-	fileQuery := db.Where("user_id = ?", userID)
+	fileQuery := scope(db.Model(&models.File{}))
 	
 	if isExtensionPattern {
 		// For extension patterns like *.pdf, search by name ending with extension
-		fileQuery = fileQuery.Where("name LIKE ?", "%."+extension)
+		fileQuery = fileQuery.Where(database.ILike("name"), "%."+extension)
 	} else {
 		// Regular name search with wildcard support
-		fileQuery = fileQuery.Where("name LIKE ?", searchPattern)
+		fileQuery = fileQuery.Where(database.ILike("name"), searchPattern)
 	}
 	if fileType != "" {
 		switch strings.ToLower(fileType) {
@@ -115,7 +133,7 @@ func SearchFiles(c *gin.Context) {
 	
 	// Search folders (only if not an extension pattern, since folders don't have extensions)
 	if !isExtensionPattern {
-		folderQuery := db.Where("user_id = ? AND name LIKE ?", userID, searchPattern)
+		folderQuery := scope(db.Model(&models.Folder{})).Where(database.ILike("name"), searchPattern)
 		if err := folderQuery.Limit(25).Find(&folders).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search folders"})
 			return
@@ -136,7 +154,7 @@ func GetFileTypes(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 	
 	var mimeTypes []string
-	if err := db.Model(&models.File{}).Where("user_id = ?", userID).
+	if err := db.Model(&models.File{}).Where("user_id = ? AND drive_id IS NULL", userID).
 		Distinct("mime_type").Pluck("mime_type", &mimeTypes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get file types"})
 		return
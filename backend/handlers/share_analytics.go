@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/config"
+	"a-drive-backend/database"
+	"a-drive-backend/geoip"
+	"a-drive-backend/logging"
+	"a-drive-backend/models"
+)
+
+// ShareAnalytics summarizes the accesses of one or more shares since From
+type ShareAnalytics struct {
+	From       time.Time          `json:"from"`
+	Summary    ShareAccessSummary `json:"summary"`
+	Timeline   []ShareActivity    `json:"timeline"`
+	UserAgents []AccessCount      `json:"user_agents"`
+	Referrers  []AccessCount      `json:"referrers"`
+	Countries  []AccessCount      `json:"countries"`
+}
+
+type ShareAccessSummary struct {
+	Views          int64      `json:"views"`
+	Downloads      int64      `json:"downloads"`
+	Previews       int64      `json:"previews"`
+	UniqueVisitors int64      `json:"unique_visitors"` // distinct client IPs
+	FirstAccessAt  *time.Time `json:"first_access_at"`
+	LastAccessAt   *time.Time `json:"last_access_at"`
+}
+
+// ShareActivity counts the accesses of one day
+type ShareActivity struct {
+	Date      string `json:"date"`
+	Views     int64  `json:"views"`
+	Downloads int64  `json:"downloads"`
+	Previews  int64  `json:"previews"`
+}
+
+// AccessCount is one entry of a top list, such as a user agent
+type AccessCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ShareStats are the totals of one share in the analytics of all shares
+type ShareStats struct {
+	ShareID        uint   `json:"share_id"`
+	FileID         *uint  `json:"file_id"`
+	FolderID       *uint  `json:"folder_id"`
+	FileName       string `json:"file_name"` // the name of the shared folder for folder shares
+	ShareType      string `json:"share_type"`
+	Views          int64  `json:"views"`
+	Downloads      int64  `json:"downloads"`
+	Previews       int64  `json:"previews"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// topListSize bounds the user agent, referrer and country lists
+const topListSize = 10
+
+// actionCounts sums up the accesses by action
+var actionCounts = []string{
+	"COALESCE(SUM(CASE WHEN share_accesses.action = 'view' THEN 1 ELSE 0 END), 0) AS views",
+	"COALESCE(SUM(CASE WHEN share_accesses.action = 'download' THEN 1 ELSE 0 END), 0) AS downloads",
+	"COALESCE(SUM(CASE WHEN share_accesses.action = 'preview' THEN 1 ELSE 0 END), 0) AS previews",
+}
+
+// GetShareAnalytics returns the analytics of one share
+func GetShareAnalytics(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	share, ok := loadManagedShare(c, db, userID)
+	if !ok {
+		return
+	}
+	since, ok := analyticsSince(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := shareAnalytics(db, []uint{share.ID}, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute share analytics"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"share_id": share.ID, "analytics": analytics})
+}
+
+// GetUserShareAnalytics returns the analytics of all shares the user created,
+// together with the totals of each share
+func GetUserShareAnalytics(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	since, ok := analyticsSince(c)
+	if !ok {
+		return
+	}
+
+	shareIDs := db.Model(&models.FileShare{}).Select("id").Where("shared_by = ?", userID)
+	analytics, err := shareAnalytics(db, shareIDs, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute share analytics"})
+		return
+	}
+
+	var shares []ShareStats
+	if err := db.Model(&models.FileShare{}).
+		Select(append([]string{
+			"file_shares.id AS share_id", "file_shares.file_id", "file_shares.folder_id",
+			"COALESCE(files.name, folders.name) AS file_name", "file_shares.share_type",
+			"COUNT(DISTINCT share_accesses.accessed_by) AS unique_visitors",
+		}, actionCounts...)).
+		Joins("LEFT JOIN files ON files.id = file_shares.file_id").
+		Joins("LEFT JOIN folders ON folders.id = file_shares.folder_id").
+		Joins("LEFT JOIN share_accesses ON share_accesses.share_id = file_shares.id AND share_accesses.accessed_at >= ?", since).
+		Where("file_shares.shared_by = ?", userID).
+		Group("file_shares.id, file_shares.file_id, file_shares.folder_id, files.name, folders.name, file_shares.share_type").
+		Order("file_shares.id").
+		Scan(&shares).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute share analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytics": analytics, "shares": shares})
+}
+
+// ExportShareAccesses downloads the accesses of one share as CSV
+func ExportShareAccesses(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	share, ok := loadManagedShare(c, db, userID)
+	if !ok {
+		return
+	}
+	since, ok := analyticsSince(c)
+	if !ok {
+		return
+	}
+	exportShareAccesses(c, db, []uint{share.ID}, since, fmt.Sprintf("share-%d-accesses.csv", share.ID))
+}
+
+// ExportUserShareAccesses downloads the accesses of all shares the user
+// created as CSV
+func ExportUserShareAccesses(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	since, ok := analyticsSince(c)
+	if !ok {
+		return
+	}
+	shareIDs := db.Model(&models.FileShare{}).Select("id").Where("shared_by = ?", userID)
+	exportShareAccesses(c, db, shareIDs, since, "share-accesses.csv")
+}
+
+// analyticsSince reads the days query parameter and returns the start of the
+// first day it covers. Days are UTC days.
+func analyticsSince(c *gin.Context) (time.Time, bool) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return time.Time{}, false
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, 1-days), true
+}
+
+// shareAnalytics computes the analytics of the shares selected by shareIDs,
+// a slice of IDs or a subquery
+func shareAnalytics(db *gorm.DB, shareIDs interface{}, since time.Time) (ShareAnalytics, error) {
+	accesses := func() *gorm.DB {
+		return db.Model(&models.ShareAccess{}).
+			Where("share_accesses.share_id IN (?) AND share_accesses.accessed_at >= ?", shareIDs, since)
+	}
+	analytics := ShareAnalytics{From: since}
+
+	if err := accesses().
+		Select(append([]string{"COUNT(DISTINCT share_accesses.accessed_by) AS unique_visitors"}, actionCounts...)).
+		Scan(&analytics.Summary).Error; err != nil {
+		return analytics, err
+	}
+	var first, last []time.Time
+	if err := accesses().Order("accessed_at").Limit(1).Pluck("accessed_at", &first).Error; err != nil {
+		return analytics, err
+	}
+	if err := accesses().Order("accessed_at DESC").Limit(1).Pluck("accessed_at", &last).Error; err != nil {
+		return analytics, err
+	}
+	if len(first) > 0 && len(last) > 0 {
+		analytics.Summary.FirstAccessAt = &first[0]
+		analytics.Summary.LastAccessAt = &last[0]
+	}
+
+	var days []ShareActivity
+	accessDate := database.DateExpr(db, "share_accesses.accessed_at")
+	if err := accesses().
+		Select(append([]string{accessDate + " AS date"}, actionCounts...)).
+		Group(accessDate).
+		Scan(&days).Error; err != nil {
+		return analytics, err
+	}
+	byDate := make(map[string]ShareActivity, len(days))
+	for _, day := range days {
+		byDate[day.Date] = day
+	}
+	for date := since; !date.After(time.Now()); date = date.AddDate(0, 0, 1) {
+		key := date.Format("2006-01-02")
+		day := byDate[key]
+		day.Date = key
+		analytics.Timeline = append(analytics.Timeline, day)
+	}
+
+	for column, list := range map[string]*[]AccessCount{
+		"user_agent": &analytics.UserAgents,
+		"referrer":   &analytics.Referrers,
+		"country":    &analytics.Countries,
+	} {
+		*list = []AccessCount{}
+		if err := accesses().
+			Select(column + " AS value, COUNT(*) AS count").
+			Where(column + " <> ''").
+			Group(column).
+			Order("count DESC, value").
+			Limit(topListSize).
+			Scan(list).Error; err != nil {
+			return analytics, err
+		}
+	}
+	return analytics, nil
+}
+
+// exportShareAccesses streams the accesses of the selected shares as CSV,
+// oldest first
+func exportShareAccesses(c *gin.Context, db *gorm.DB, shareIDs interface{}, since time.Time, filename string) {
+	rows, err := db.Model(&models.ShareAccess{}).
+		Select("share_accesses.accessed_at, share_accesses.share_id, COALESCE(files.name, folders.name), share_accesses.action, share_accesses.accessed_by, share_accesses.country, share_accesses.user_agent, share_accesses.referrer").
+		Joins("JOIN file_shares ON file_shares.id = share_accesses.share_id").
+		Joins("LEFT JOIN files ON files.id = file_shares.file_id").
+		Joins("LEFT JOIN folders ON folders.id = file_shares.folder_id").
+		Where("share_accesses.share_id IN (?) AND share_accesses.accessed_at >= ?", shareIDs, since).
+		Order("share_accesses.accessed_at, share_accesses.id").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export share accesses"})
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	out := csv.NewWriter(c.Writer)
+	out.Write([]string{"accessed_at", "share_id", "file", "action", "visitor", "country", "user_agent", "referrer"})
+	for rows.Next() {
+		var accessedAt time.Time
+		var shareID uint
+		var file, action, visitor, country, userAgent, referrer string
+		if err := rows.Scan(&accessedAt, &shareID, &file, &action, &visitor, &country, &userAgent, &referrer); err != nil {
+			logging.FromContext(c.Request.Context()).Error("Share access export failed", "error", err)
+			break
+		}
+		out.Write([]string{
+			accessedAt.UTC().Format(time.RFC3339), strconv.FormatUint(uint64(shareID), 10),
+			csvText(file), action, csvText(visitor), csvText(country), csvText(userAgent), csvText(referrer),
+		})
+	}
+	out.Flush()
+}
+
+// csvText keeps a text cell from being read as a formula by spreadsheets.
+// Visitors choose their user agent and referrer, and a cell such as
+// =HYPERLINK(...) would otherwise become live when the owner opens the export.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// notifyFirstDownload tells the creator of a share that asked for it that the
+// share was downloaded for the first time
+func notifyFirstDownload(db *gorm.DB, share *models.FileShare) {
+	// Only the request that sets the time sends the notification
+	result := db.Model(&models.FileShare{}).
+		Where("id = ? AND first_downloaded_at IS NULL", share.ID).
+		Update("first_downloaded_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 || !share.NotifyOnFirstDownload {
+		return
+	}
+
+	notification := models.Notification{
+		UserID:  share.SharedBy,
+		Type:    models.NotificationShareFirstDownload,
+		Message: fmt.Sprintf("%s was downloaded through a share link for the first time", share.ItemName()),
+		FileID:  share.FileID,
+		ShareID: &share.ID,
+	}
+	if err := db.Create(&notification).Error; err != nil {
+		logging.FromContext(db.Statement.Context).Error("Failed to notify of the first download of a share", "share_id", share.ID, "owner_id", share.SharedBy, "error", err)
+	}
+}
+
+var (
+	geoIPOnce sync.Once
+	geoIPDB   *geoip.DB
+)
+
+// visitorCountry looks up the country of a share visitor in the GeoIP
+// database, if one is configured
+func visitorCountry(ip string) string {
+	geoIPOnce.Do(func() {
+		path := config.Load().GeoIPDatabase
+		if path == "" {
+			return
+		}
+		db, err := geoip.Open(path)
+		if err != nil {
+			slog.Warn("GeoIP database not loaded", "path", path, "error", err)
+			return
+		}
+		slog.Info("GeoIP database loaded", "path", path, "ranges", db.Len())
+		geoIPDB = db
+	})
+	if geoIPDB == nil {
+		return ""
+	}
+	return geoIPDB.Country(ip)
+}
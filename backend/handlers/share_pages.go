@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"a-drive-backend/logging"
+	"a-drive-backend/models"
+	"a-drive-backend/pages"
+	"a-drive-backend/preview"
+)
+
+// shareDetails are shown on the landing pages of both file and folder shares
+type shareDetails struct {
+	Link          string
+	SharedBy      string
+	ExpiresAt     *time.Time
+	Limited       bool
+	DownloadsLeft int
+}
+
+// sharePage is the data of the landing page of a file share
+type sharePage struct {
+	shareDetails
+	Name    string
+	Size    int64
+	Preview string // image, video, audio, pdf or document; empty without a preview
+}
+
+// folderPage is the data of the landing page of a folder share, which lists
+// the shared folder or one of its subfolders
+type folderPage struct {
+	shareDetails
+	Name    string
+	Trail   []models.Folder // from the shared folder down to the parent of the listed one
+	Folders []models.Folder
+	Files   []folderPageFile
+}
+
+type folderPageFile struct {
+	ID      uint
+	Name    string
+	Size    int64
+	Preview bool
+}
+
+type passwordPage struct {
+	Link     string
+	SharedBy string
+	Item     string // file or folder
+	Error    string
+}
+
+type errorPage struct {
+	Title   string
+	Message string
+	Link    string
+}
+
+// shareErrorHints explain share errors to people on the error page
+var shareErrorHints = map[int]string{
+	http.StatusNotFound:        "The link may be mistyped, or the share has been deleted.",
+	http.StatusForbidden:       "The owner of this share does not allow it.",
+	http.StatusGone:            "Ask the person who shared it for a new link.",
+	http.StatusUnauthorized:    "Enter the password of the share first.",
+	http.StatusTooManyRequests: "Wait a few minutes before trying again.",
+}
+
+// OpenShare answers GET /share/:token with a landing page for browsers and
+// with the share as JSON for API clients
+func OpenShare(c *gin.Context) {
+	if !wantsHTML(c) {
+		AccessSharedFile(c)
+		return
+	}
+	db := c.MustGet("db").(*gorm.DB)
+
+	share, ok := loadShare(c, db, "SharedByUser")
+	if !ok {
+		return
+	}
+
+	if share.ShareType == "password" && !hasShareSession(c, &share) {
+		renderPage(c, http.StatusOK, "password", passwordPage{
+			Link:     shareLinkPath(c),
+			SharedBy: share.SharedByUser.Username,
+			Item:     sharedItemType(&share),
+		})
+		return
+	}
+
+	details := shareDetails{
+		Link:      shareLinkPath(c),
+		SharedBy:  share.SharedByUser.Username,
+		ExpiresAt: share.ExpiresAt,
+	}
+	if share.MaxDownloads != nil {
+		details.Limited = true
+		details.DownloadsLeft = *share.MaxDownloads - share.DownloadCount
+	}
+
+	if share.Folder != nil {
+		openFolderShare(c, db, &share, details)
+		return
+	}
+
+	logShareAccess(c, db, share.ID, "view")
+
+	page := sharePage{
+		shareDetails: details,
+		Name:         share.File.Name,
+		Size:         share.File.Size,
+	}
+	if share.AllowPreview {
+		switch kind := preview.Kind(share.File.Name, share.File.MimeType); kind {
+		case preview.Image, preview.Video, preview.Audio, preview.PDF:
+			page.Preview = kind
+		case "":
+		default:
+			page.Preview = "document"
+		}
+	}
+	renderPage(c, http.StatusOK, "share", page)
+}
+
+// openFolderShare renders the landing page of a folder share, listing the
+// folder in the folder query parameter
+func openFolderShare(c *gin.Context, db *gorm.DB, share *models.FileShare, details shareDetails) {
+	folder, ok := sharedFolder(c, db, share)
+	if !ok {
+		return
+	}
+	folders, files, err := folderContents(db, folder)
+	if err != nil {
+		shareError(c, http.StatusInternalServerError, "Failed to fetch folder contents")
+		return
+	}
+
+	logShareAccess(c, db, share.ID, "view")
+
+	page := folderPage{shareDetails: details, Name: folder.Name, Folders: folders}
+	for current := folder; current.ID != share.Folder.ID && current.ParentID != nil; {
+		var parent models.Folder
+		if err := db.Where("id = ?", *current.ParentID).First(&parent).Error; err != nil {
+			break
+		}
+		page.Trail = append([]models.Folder{parent}, page.Trail...)
+		current = parent
+	}
+	for _, file := range files {
+		page.Files = append(page.Files, folderPageFile{
+			ID:      file.ID,
+			Name:    file.Name,
+			Size:    file.Size,
+			Preview: share.AllowPreview && preview.Kind(file.Name, file.MimeType) != "",
+		})
+	}
+	renderPage(c, http.StatusOK, "folder", page)
+}
+
+// sharedItemType is "folder" for folder shares and "file" for file shares
+func sharedItemType(share *models.FileShare) string {
+	if share.FolderID != nil {
+		return "folder"
+	}
+	return "file"
+}
+
+// UnlockShare checks the password submitted with the form of a password
+// share's landing page and starts a session in a cookie
+func UnlockShare(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	share, ok := loadShare(c, db, "SharedByUser")
+	if !ok {
+		return
+	}
+	if share.ShareType != "password" || hasShareSession(c, &share) {
+		c.Redirect(http.StatusSeeOther, shareLinkPath(c))
+		return
+	}
+	if throttleSharePassword(c, &share) {
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(share.Password), []byte(c.PostForm("password"))); err != nil {
+		recordSharePasswordFailure(c, &share)
+		renderPage(c, http.StatusUnauthorized, "password", passwordPage{
+			Link:     shareLinkPath(c),
+			SharedBy: share.SharedByUser.Username,
+			Item:     sharedItemType(&share),
+			Error:    "Incorrect password",
+		})
+		return
+	}
+	recordSharePasswordSuccess(c)
+
+	if _, _, err := startShareSession(c, &share); err != nil {
+		shareError(c, http.StatusInternalServerError, "Failed to start share session")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, shareLinkPath(c))
+}
+
+// wantsHTML reports whether the client prefers a page to JSON, as browsers
+// opening a share link do. Clients that accept anything get JSON.
+func wantsHTML(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// shareLinkPath is the path of the landing page the request was made under,
+// with the share's token or slug
+func shareLinkPath(c *gin.Context) string {
+	return "/share/" + c.Param("token")
+}
+
+// shareError writes an error of the public share endpoints, as an error page
+// for browsers
+func shareError(c *gin.Context, status int, message string) {
+	if !wantsHTML(c) {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	page := errorPage{Title: message, Message: shareErrorHints[status]}
+	if status == http.StatusUnauthorized || status == http.StatusTooManyRequests {
+		page.Link = shareLinkPath(c)
+	}
+	renderPage(c, status, "error", page)
+}
+
+// renderPage writes an HTML page with headers that keep it from being framed,
+// cached or leaking the share link in the Referer header
+func renderPage(c *gin.Context, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := pages.Render(&buf, name, data); err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to render page", "page", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render page"})
+		return
+	}
+
+	c.Header("Content-Security-Policy", pages.CSP)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/config"
+	"a-drive-backend/models"
+	"a-drive-backend/utils"
+)
+
+// shareSessionCookie prefixes the cookies that carry the sessions of unlocked
+// password shares. Each share has its own, named after its ID and sent to all
+// of /share/, so that it covers both the token and the slug URL.
+const shareSessionCookie = "share_session_"
+
+func shareSessionCookieName(share *models.FileShare) string {
+	return shareSessionCookie + strconv.FormatUint(uint64(share.ID), 10)
+}
+
+// attemptLimiter counts failed attempts per key within a sliding window
+type attemptLimiter struct {
+	mu       sync.Mutex
+	max      int
+	window   time.Duration
+	failures map[string][]time.Time
+}
+
+func newAttemptLimiter(max int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{max: max, window: window, failures: map[string][]time.Time{}}
+}
+
+// retryAfter returns how long key has to wait before its next attempt, or 0
+// when it may try now
+func (l *attemptLimiter) retryAfter(key string, now time.Time) time.Duration {
+	if l.max <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := l.recent(key, now)
+	if len(recent) < l.max {
+		return 0
+	}
+	return recent[len(recent)-l.max].Add(l.window).Sub(now)
+}
+
+func (l *attemptLimiter) fail(key string, now time.Time) {
+	if l.max <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget keys that have not failed within the window so that the map
+	// does not grow with every address that ever mistyped a password
+	if len(l.failures) > 10000 {
+		for k := range l.failures {
+			l.recent(k, now)
+		}
+	}
+	l.failures[key] = append(l.recent(key, now), now)
+}
+
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
+
+// recent drops the failures of key that are older than the window
+func (l *attemptLimiter) recent(key string, now time.Time) []time.Time {
+	times := l.failures[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= l.window {
+		i++
+	}
+	if i == len(times) {
+		delete(l.failures, key)
+		return nil
+	}
+	times = times[i:]
+	l.failures[key] = times
+	return times
+}
+
+var (
+	shareLimitersOnce sync.Once
+	shareTokenLimiter *attemptLimiter
+	shareIPLimiter    *attemptLimiter
+)
+
+func shareLimiters() (*attemptLimiter, *attemptLimiter) {
+	shareLimitersOnce.Do(func() {
+		cfg := config.Load()
+		shareTokenLimiter = newAttemptLimiter(cfg.SharePasswordAttempts, cfg.SharePasswordWindow)
+		shareIPLimiter = newAttemptLimiter(cfg.SharePasswordIPAttempts, cfg.SharePasswordWindow)
+	})
+	return shareTokenLimiter, shareIPLimiter
+}
+
+// throttleSharePassword refuses a password attempt with 429 while the share
+// or the client has too many recent failures
+func throttleSharePassword(c *gin.Context, share *models.FileShare) bool {
+	byToken, byIP := shareLimiters()
+	now := time.Now()
+	wait := byToken.retryAfter(share.ShareToken, now)
+	if ipWait := byIP.retryAfter(c.ClientIP(), now); ipWait > wait {
+		wait = ipWait
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	shareError(c, http.StatusTooManyRequests, "Too many failed password attempts, try again later")
+	return true
+}
+
+func recordSharePasswordFailure(c *gin.Context, share *models.FileShare) {
+	byToken, byIP := shareLimiters()
+	now := time.Now()
+	byToken.fail(share.ShareToken, now)
+	byIP.fail(c.ClientIP(), now)
+}
+
+// Failures counted against the share itself are kept, so a correct password
+// does not give a guesser a fresh budget
+func recordSharePasswordSuccess(c *gin.Context) {
+	_, byIP := shareLimiters()
+	byIP.reset(c.ClientIP())
+}
+
+// shareSessionKey ties a session to the share's current password
+func shareSessionKey(share *models.FileShare) string {
+	sum := sha256.Sum256([]byte(share.Password))
+	return hex.EncodeToString(sum[:8])
+}
+
+// startShareSession issues a session for an unlocked password share, as a
+// cookie for browsers and in the response for other clients
+func startShareSession(c *gin.Context, share *models.FileShare) (string, time.Time, error) {
+	ttl := config.Load().ShareSessionTTL
+	token, expiresAt, err := utils.GenerateShareSessionToken(share.ID, shareSessionKey(share), ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(shareSessionCookieName(share), token, int(ttl.Seconds()), "/share/", "", secure, true)
+	return token, expiresAt, nil
+}
+
+// hasShareSession reports whether the request carries a valid session for
+// the share, in the session query parameter or the share's cookie
+func hasShareSession(c *gin.Context, share *models.FileShare) bool {
+	token := c.Query("session")
+	if token == "" {
+		token, _ = c.Cookie(shareSessionCookieName(share))
+	}
+	if token == "" {
+		return false
+	}
+
+	claims, err := utils.ValidateShareSessionToken(token)
+	if err != nil {
+		return false
+	}
+	return claims.ShareID == share.ID && claims.Key == shareSessionKey(share)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+	"a-drive-backend/sharing"
+)
+
+// UpdateShareRequest changes the settings of a share. Omitted fields are left
+// unchanged. MaxDownloads of 0 and an empty Slug remove the limit and the slug.
+type UpdateShareRequest struct {
+	ShareType             *string    `json:"share_type" binding:"omitempty,oneof=public password private"`
+	Password              *string    `json:"password"`
+	ExpiresAt             *time.Time `json:"expires_at"`
+	RemoveExpiry          bool       `json:"remove_expiry"`
+	MaxDownloads          *int       `json:"max_downloads" binding:"omitempty,min=0"`
+	AllowPreview          *bool      `json:"allow_preview"`
+	NotifyOnFirstDownload *bool      `json:"notify_on_first_download"`
+	Slug                  *string    `json:"slug"`
+}
+
+var (
+	shareSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}[a-z0-9]$`)
+	// Slugs must not be mistaken for generated tokens
+	shareTokenPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// UpdateFileShare changes the settings of a share. Changing the password or
+// the share type ends the sessions of password-protected shares, and a new
+// expiry or download limit makes an ended share active again.
+func UpdateFileShare(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	var req UpdateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	share, ok := loadManagedShare(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if req.ShareType != nil {
+		share.ShareType = *req.ShareType
+	}
+	if share.ShareType != "password" {
+		share.Password = ""
+	} else if req.Password != nil && *req.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		share.Password = string(hashedPassword)
+	}
+	if share.ShareType == "password" && share.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password required for password-protected shares"})
+		return
+	}
+
+	if req.RemoveExpiry {
+		share.ExpiresAt = nil
+	} else if req.ExpiresAt != nil {
+		share.ExpiresAt = req.ExpiresAt
+	}
+	if req.MaxDownloads != nil {
+		share.MaxDownloads = req.MaxDownloads
+		if *req.MaxDownloads == 0 {
+			share.MaxDownloads = nil
+		}
+	}
+	if req.AllowPreview != nil {
+		share.AllowPreview = *req.AllowPreview
+	}
+	if req.NotifyOnFirstDownload != nil {
+		share.NotifyOnFirstDownload = *req.NotifyOnFirstDownload
+	}
+	if req.Slug != nil {
+		slug, ok := checkShareSlug(c, db, *req.Slug, share.ID)
+		if !ok {
+			return
+		}
+		share.Slug = slug
+	}
+	share.Status = sharing.StatusAt(&share, time.Now())
+
+	if err := db.Model(&share).Select(
+		"share_type", "password", "expires_at", "max_downloads", "allow_preview",
+		"notify_on_first_download", "slug", "status",
+	).Updates(&share).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update share"})
+		return
+	}
+
+	respondShare(c, db, &share, "Share updated successfully")
+}
+
+// DisableFileShare turns a share link off without deleting it
+func DisableFileShare(c *gin.Context) {
+	setShareDisabled(c, true)
+}
+
+// EnableFileShare turns a disabled share link back on
+func EnableFileShare(c *gin.Context) {
+	setShareDisabled(c, false)
+}
+
+func setShareDisabled(c *gin.Context, disabled bool) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	share, ok := loadManagedShare(c, db, userID)
+	if !ok {
+		return
+	}
+
+	share.Disabled = disabled
+	if err := db.Model(&share).Update("disabled", disabled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update share"})
+		return
+	}
+
+	message := "Share enabled successfully"
+	if disabled {
+		message = "Share disabled successfully"
+	}
+	respondShare(c, db, &share, message)
+}
+
+func respondShare(c *gin.Context, db *gorm.DB, share *models.FileShare, message string) {
+	db.Preload("File").Preload("Folder").Preload("SharedByUser").First(share, share.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"share":     share,
+		"share_url": getShareURL(c, shareLinkID(share)),
+	})
+}
+
+// checkShareSlug validates a requested slug and checks that no other share
+// uses it, writing the error response itself. An empty slug returns nil.
+func checkShareSlug(c *gin.Context, db *gorm.DB, slug string, shareID uint) (*string, bool) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		return nil, true
+	}
+	if !shareSlugPattern.MatchString(slug) || shareTokenPattern.MatchString(slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slugs are 3 to 64 letters, digits and hyphens, and cannot start or end with a hyphen"})
+		return nil, false
+	}
+
+	var count int64
+	db.Model(&models.FileShare{}).
+		Where("(slug = ? OR share_token = ?) AND id <> ?", slug, slug, shareID).
+		Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug is already in use"})
+		return nil, false
+	}
+	return &slug, true
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"archive/zip"
 	"crypto/rand"
 	"encoding/hex"
+	"mime"
 	"net/http"
 	"time"
 
@@ -10,7 +12,11 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/logging"
+	"a-drive-backend/metrics"
 	"a-drive-backend/models"
+	"a-drive-backend/sharing"
 )
 
 type CreateShareRequest struct {
@@ -18,7 +24,9 @@ type CreateShareRequest struct {
 	Password      string     `json:"password"`
 	ExpiresAt     *time.Time `json:"expires_at"`
 	MaxDownloads  *int       `json:"max_downloads"`
-	AllowPreview  bool       `json:"allow_preview"`
+	AllowPreview  *bool      `json:"allow_preview"` // defaults to true
+	NotifyOnFirstDownload bool `json:"notify_on_first_download"`
+	Slug          string     `json:"slug"`
 }
 
 type ShareAccessRequest struct {
@@ -27,9 +35,33 @@ type ShareAccessRequest struct {
 
 func CreateFileShare(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
-	fileID := c.Param("id")
+	// Verify the user may share the file; team drive files need the manager role
+	file, ok := loadFile(c, db, c.Param("id"), authz.SharesCreate)
+	if !ok {
+		return
+	}
+	
+	createShare(c, db, models.FileShare{FileID: &file.ID}, "File shared successfully")
+}
+
+// CreateFolderShare creates a share link through which the folder and
+// everything inside it can be browsed and downloaded
+func CreateFolderShare(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.SharesCreate)
+	if !ok {
+		return
+	}
+	
+	createShare(c, db, models.FileShare{FolderID: &folder.ID}, "Folder shared successfully")
+}
+
+// createShare creates a share of the file or folder set in share with the
+// settings in the request body
+func createShare(c *gin.Context, db *gorm.DB, share models.FileShare, message string) {
+	userID := c.MustGet("user_id").(uint)
 	
 	var req CreateShareRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -37,10 +69,13 @@ func CreateFileShare(c *gin.Context) {
 		return
 	}
 	
-	// Verify file exists and belongs to user
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	if req.ShareType == "password" && req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password required for password-protected shares"})
+		return
+	}
+	
+	slug, ok := checkShareSlug(c, db, req.Slug, 0)
+	if !ok {
 		return
 	}
 	
@@ -51,16 +86,17 @@ func CreateFileShare(c *gin.Context) {
 		return
 	}
 	
-	// Create share record
-	share := models.FileShare{
-		FileID:       file.ID,
-		SharedBy:     userID,
-		ShareToken:   token,
-		ShareType:    req.ShareType,
-		ExpiresAt:    req.ExpiresAt,
-		MaxDownloads: req.MaxDownloads,
-		AllowPreview: req.AllowPreview,
-	}
+	allowPreview := req.AllowPreview == nil || *req.AllowPreview
+	
+	share.SharedBy = userID
+	share.ShareToken = token
+	share.ShareType = req.ShareType
+	share.ExpiresAt = req.ExpiresAt
+	share.MaxDownloads = req.MaxDownloads
+	share.AllowPreview = allowPreview
+	share.NotifyOnFirstDownload = req.NotifyOnFirstDownload
+	share.Slug = slug
+	share.Status = sharing.StatusAt(&share, time.Now())
 	
 	// Hash password if provided
 	if req.ShareType == "password" && req.Password != "" {
@@ -72,58 +108,68 @@ func CreateFileShare(c *gin.Context) {
 		share.Password = string(hashedPassword)
 	}
 	
-	if err := db.Create(&share).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&share).Error; err != nil {
+			return err
+		}
+		// gorm writes the column default, true, in place of false
+		if !allowPreview {
+			return tx.Model(&share).Update("allow_preview", false).Error
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share"})
 		return
 	}
 	
 	// Load the created share with relationships
-	db.Preload("File").Preload("SharedByUser").First(&share, share.ID)
+	db.Preload("File").Preload("Folder").Preload("SharedByUser").First(&share, share.ID)
 	
 	c.JSON(http.StatusOK, gin.H{
-		"message": "File shared successfully",
+		"message": message,
 		"share":   share,
-		"share_url": getShareURL(c, token),
+		"share_url": getShareURL(c, shareLinkID(&share)),
 	})
 }
 
 func GetFileShares(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	// Verify file exists and belongs to user
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.SharesCreate)
+	if !ok {
 		return
 	}
 	
-	var shares []models.FileShare
-	if err := db.Where("file_id = ?", file.ID).
-		Preload("SharedByUser").
-		Order("created_at DESC").
-		Find(&shares).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shares"})
-		return
-	}
+	listShares(c, db.Where("file_id = ?", file.ID))
+}
+
+// GetFolderShares lists the share links of a folder
+func GetFolderShares(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 	
-	// Add share URLs
-	for i := range shares {
-		shares[i].ShareToken = getShareURL(c, shares[i].ShareToken)
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.SharesCreate)
+	if !ok {
+		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"shares": shares})
+	listShares(c, db.Where("folder_id = ?", folder.ID))
 }
 
 func GetUserShares(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 	
+	listShares(c, db.Where("shared_by = ?", userID).Preload("File").Preload("Folder"))
+}
+
+// listShares responds with the shares the query selects, newest first, with
+// their full URL in ShareToken
+func listShares(c *gin.Context, query *gorm.DB) {
 	var shares []models.FileShare
-	if err := db.Where("shared_by = ?", userID).
-		Preload("File").
+	if err := query.
 		Preload("SharedByUser").
 		Order("created_at DESC").
 		Find(&shares).Error; err != nil {
@@ -133,24 +179,52 @@ func GetUserShares(c *gin.Context) {
 	
 	// Add share URLs
 	for i := range shares {
-		shares[i].ShareToken = getShareURL(c, shares[i].ShareToken)
+		shares[i].ShareToken = getShareURL(c, shareLinkID(&shares[i]))
 	}
 	
 	c.JSON(http.StatusOK, gin.H{"shares": shares})
 }
 
+// loadManagedShare finds the share in the share_id path parameter for its
+// creator or, for team drive items, a drive manager, writing the error
+// response itself
+func loadManagedShare(c *gin.Context, db *gorm.DB, userID uint) (models.FileShare, bool) {
+	var share models.FileShare
+	if err := db.Where("id = ?", c.Param("share_id")).First(&share).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return share, false
+	}
+	
+	// Drive managers may manage shares created by other members
+	if share.SharedBy != userID {
+		var err error
+		if share.FolderID != nil {
+			_, err = findFolder(db, *share.FolderID, userID, authz.SharesCreate)
+		} else {
+			_, err = findFile(db, *share.FileID, userID, authz.SharesCreate)
+		}
+		if err != nil {
+			respondAccessError(c, err, "Share not found")
+			return share, false
+		}
+	}
+	return share, true
+}
+
 func DeleteFileShare(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("user_id").(uint)
 	
-	shareID := c.Param("share_id")
-	
-	var share models.FileShare
-	if err := db.Where("id = ? AND shared_by = ?", shareID, userID).First(&share).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+	share, ok := loadManagedShare(c, db, userID)
+	if !ok {
 		return
 	}
 	
+	// Free the slug for new shares; deleted shares keep their row
+	if err := db.Model(&share).Update("slug", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share"})
+		return
+	}
 	if err := db.Delete(&share).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share"})
 		return
@@ -163,31 +237,19 @@ func DeleteFileShare(c *gin.Context) {
 func AccessSharedFile(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	
-	token := c.Param("token")
-	
-	var share models.FileShare
-	if err := db.Where("share_token = ?", token).
-		Preload("File").
-		Preload("SharedByUser").
-		First(&share).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
-		return
-	}
-	
-	// Check if share has expired
-	if share.ExpiresAt != nil && time.Now().After(*share.ExpiresAt) {
-		c.JSON(http.StatusGone, gin.H{"error": "Share has expired"})
-		return
-	}
-	
-	// Check download limit
-	if share.MaxDownloads != nil && share.DownloadCount >= *share.MaxDownloads {
-		c.JSON(http.StatusGone, gin.H{"error": "Download limit exceeded"})
+	share, ok := loadShare(c, db, "SharedByUser")
+	if !ok {
 		return
 	}
 	
-	// For password-protected shares, require password verification
-	if share.ShareType == "password" {
+	// For password-protected shares, require a session or the password.
+	// A correct password starts a session for the download and preview.
+	var session gin.H
+	if share.ShareType == "password" && !hasShareSession(c, &share) {
+		if throttleSharePassword(c, &share) {
+			return
+		}
+		
 		var req ShareAccessRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Password required"})
@@ -195,63 +257,231 @@ func AccessSharedFile(c *gin.Context) {
 		}
 		
 		if err := bcrypt.CompareHashAndPassword([]byte(share.Password), []byte(req.Password)); err != nil {
+			recordSharePasswordFailure(c, &share)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
 			return
 		}
+		recordSharePasswordSuccess(c)
+		
+		token, expiresAt, err := startShareSession(c, &share)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start share session"})
+			return
+		}
+		session = gin.H{"session_token": token, "session_expires_at": expiresAt}
 	}
 	
-	// Log the access
-	logShareAccess(db, share.ID, c.ClientIP(), c.GetHeader("User-Agent"), "view")
-	
 	// Return share info (without sensitive data)
 	shareInfo := gin.H{
 		"id":            share.ID,
-		"file":          share.File,
 		"shared_by":     share.SharedByUser.Username,
 		"share_type":    share.ShareType,
 		"allow_preview": share.AllowPreview,
 		"created_at":    share.CreatedAt,
 	}
+	if share.Folder != nil {
+		// Folder shares list the folder in the folder query parameter
+		folder, ok := sharedFolder(c, db, &share)
+		if !ok {
+			return
+		}
+		folders, files, err := folderContents(db, folder)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folder contents"})
+			return
+		}
+		shareInfo["folder"] = share.Folder
+		shareInfo["current_folder"] = folder
+		shareInfo["folders"] = folders
+		shareInfo["files"] = files
+	} else {
+		shareInfo["file"] = share.File
+	}
+	for key, value := range session {
+		shareInfo[key] = value
+	}
+	
+	// Log the access
+	logShareAccess(c, db, share.ID, "view")
 	
 	c.JSON(http.StatusOK, shareInfo)
 }
 
+// DownloadSharedFile downloads the shared file, a file inside a shared folder
+// or, for folder shares without a file, the whole folder as a zip
 func DownloadSharedFile(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	
-	token := c.Param("token")
+	share, ok := loadShare(c, db)
+	if !ok {
+		return
+	}
 	
-	var share models.FileShare
-	if err := db.Where("share_token = ?", token).
-		Preload("File").
-		First(&share).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+	if !requireShareSession(c, &share) {
 		return
 	}
 	
-	// Check if share has expired
-	if share.ExpiresAt != nil && time.Now().After(*share.ExpiresAt) {
-		c.JSON(http.StatusGone, gin.H{"error": "Share has expired"})
+	if share.Folder != nil && c.Param("file_id") == "" {
+		countShareDownload(c, db, &share)
+		
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": share.Folder.Name + ".zip"}))
+		c.Header("Content-Type", "application/zip")
+		zipWriter := zip.NewWriter(c.Writer)
+		if err := addFolderToZip(db, zipWriter, *share.Folder, ""); err != nil {
+			// The response has started, so the client gets a truncated zip
+			logging.FromContext(c.Request.Context()).Error("Failed to write shared folder zip", "share_id", share.ID, "error", err)
+			return
+		}
+		zipWriter.Close()
 		return
 	}
 	
-	// Check download limit
-	if share.MaxDownloads != nil && share.DownloadCount >= *share.MaxDownloads {
-		c.JSON(http.StatusGone, gin.H{"error": "Download limit exceeded"})
+	file, ok := sharedFile(c, db, &share)
+	if !ok {
 		return
 	}
 	
-	// For password-protected shares, password should be verified in previous step
-	// This endpoint assumes the user has already been authenticated via AccessSharedFile
+	countShareDownload(c, db, &share)
 	
+	// Serve the file
+	c.FileAttachment(file.FilePath, file.Name)
+}
+
+// countShareDownload counts and logs a download of the share
+func countShareDownload(c *gin.Context, db *gorm.DB, share *models.FileShare) {
 	// Increment download count
-	db.Model(&share).Update("download_count", gorm.Expr("download_count + 1"))
+	db.Model(share).Update("download_count", gorm.Expr("download_count + 1"))
 	
 	// Log the download
-	logShareAccess(db, share.ID, c.ClientIP(), c.GetHeader("User-Agent"), "download")
+	logShareAccess(c, db, share.ID, "download")
+	notifyFirstDownload(db, share)
+	metrics.ShareDownloads.Inc()
+}
+
+// PreviewSharedFile serves the shared file, or a file inside a shared folder,
+// inline for viewing in the browser. Previews do not count as downloads.
+func PreviewSharedFile(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 	
-	// Serve the file
-	c.FileAttachment(share.File.FilePath, share.File.Name)
+	share, ok := loadShare(c, db)
+	if !ok {
+		return
+	}
+	
+	if !share.AllowPreview {
+		shareError(c, http.StatusForbidden, "Preview is not allowed for this share")
+		return
+	}
+	
+	if !requireShareSession(c, &share) {
+		return
+	}
+	
+	file, ok := sharedFile(c, db, &share)
+	if !ok {
+		return
+	}
+	
+	logShareAccess(c, db, share.ID, "preview")
+	serveFilePreview(c, file, shareError)
+}
+
+// loadShare finds the share of the token or slug in the path, with the shared
+// file or folder, and checks that it can still be used, writing the error
+// response itself
+func loadShare(c *gin.Context, db *gorm.DB, preloads ...string) (models.FileShare, bool) {
+	query := db.Where("share_token = ? OR slug = ?", c.Param("token"), c.Param("token")).
+		Preload("File").
+		Preload("Folder")
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+	
+	// Shares of deleted items are gone with them
+	var share models.FileShare
+	if err := query.First(&share).Error; err != nil || (share.File == nil && share.Folder == nil) {
+		shareError(c, http.StatusNotFound, "Share not found")
+		return share, false
+	}
+	
+	if share.Disabled {
+		shareError(c, http.StatusForbidden, "Share has been disabled")
+		return share, false
+	}
+	
+	// Check expiry and download limit
+	switch sharing.StatusAt(&share, time.Now()) {
+	case models.ShareExpired:
+		shareError(c, http.StatusGone, "Share has expired")
+		return share, false
+	case models.ShareExhausted:
+		shareError(c, http.StatusGone, "Download limit exceeded")
+		return share, false
+	}
+	
+	return share, true
+}
+
+// sharedFile is the file a download or preview is for: the shared file or,
+// for folder shares, the file in the file_id path parameter, which must be
+// inside the shared folder. It writes the error response itself.
+func sharedFile(c *gin.Context, db *gorm.DB, share *models.FileShare) (*models.File, bool) {
+	fileID := c.Param("file_id")
+	if share.Folder == nil {
+		if fileID != "" {
+			shareError(c, http.StatusNotFound, "File not found")
+			return nil, false
+		}
+		return share.File, true
+	}
+	
+	var file models.File
+	if fileID == "" || db.Where("id = ?", fileID).First(&file).Error != nil ||
+		file.FolderID == nil || !isDescendantOf(db, *file.FolderID, share.Folder.ID) {
+		shareError(c, http.StatusNotFound, "File not found")
+		return nil, false
+	}
+	return &file, true
+}
+
+// sharedFolder is the folder of a folder share in the folder query
+// parameter, the shared folder itself when it is not set. Folders outside the
+// shared one are not found. It writes the error response itself.
+func sharedFolder(c *gin.Context, db *gorm.DB, share *models.FileShare) (models.Folder, bool) {
+	folderID := c.Query("folder")
+	if folderID == "" {
+		return *share.Folder, true
+	}
+	
+	var folder models.Folder
+	if db.Where("id = ?", folderID).First(&folder).Error != nil || !isDescendantOf(db, folder.ID, share.Folder.ID) {
+		shareError(c, http.StatusNotFound, "Folder not found")
+		return folder, false
+	}
+	return folder, true
+}
+
+// folderContents lists the subfolders and files of a folder by name
+func folderContents(db *gorm.DB, folder models.Folder) ([]models.Folder, []models.File, error) {
+	var folders []models.Folder
+	if err := db.Where("parent_id = ?", folder.ID).Order("name").Find(&folders).Error; err != nil {
+		return nil, nil, err
+	}
+	var files []models.File
+	if err := db.Where("folder_id = ?", folder.ID).Order("name").Find(&files).Error; err != nil {
+		return nil, nil, err
+	}
+	return folders, files, nil
+}
+
+// requireShareSession rejects requests for the content of a password share
+// that do not carry a session from AccessSharedFile
+func requireShareSession(c *gin.Context, share *models.FileShare) bool {
+	if share.ShareType != "password" || hasShareSession(c, share) {
+		return true
+	}
+	shareError(c, http.StatusUnauthorized, "Password required")
+	return false
 }
 
 // Helper functions
@@ -263,6 +493,14 @@ func generateShareToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// shareLinkID is the part of the share URL after /share/
+func shareLinkID(share *models.FileShare) string {
+	if share.Slug != nil {
+		return *share.Slug
+	}
+	return share.ShareToken
+}
+
 func getShareURL(c *gin.Context, token string) string {
 	scheme := "http"
 	if c.GetHeader("X-Forwarded-Proto") == "https" {
@@ -277,13 +515,15 @@ func getShareURL(c *gin.Context, token string) string {
 	return scheme + "://" + host + "/share/" + token
 }
 
-func logShareAccess(db *gorm.DB, shareID uint, ip, userAgent, action string) {
+func logShareAccess(c *gin.Context, db *gorm.DB, shareID uint, action string) {
 	access := models.ShareAccess{
 		ShareID:    shareID,
-		AccessedBy: ip,
+		AccessedBy: c.ClientIP(),
 		AccessedAt: time.Now(),
-		UserAgent:  userAgent,
+		UserAgent:  c.GetHeader("User-Agent"),
 		Action:     action,
+		Referrer:   c.Request.Referer(),
+		Country:    visitorCountry(c.ClientIP()),
 	}
 	db.Create(&access)
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+	"a-drive-backend/versioning"
+)
+
+var errInvalidConflictPolicy = errors.New("on_conflict must be one of reject, keep_both, overwrite, skip")
+
+// Upload outcomes reported to clients
+const (
+	uploadCreated  = "created"
+	uploadRenamed  = "renamed"
+	uploadReplaced = "replaced"
+	uploadSkipped  = "skipped"
+)
+
+// upload is one file to store
+type upload struct {
+	Name     string
+	MimeType string
+	Size     int64 // as announced by the client, for the quota check
+	Content  io.Reader
+}
+
+// parseConflictPolicy validates the on_conflict field of an upload. Uploads
+// without one keep both files, as uploads did before there were policies.
+func parseConflictPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return conflictKeepBoth, nil
+	case conflictReject, conflictKeepBoth, conflictOverwrite, conflictSkip:
+		return policy, nil
+	}
+	return "", errInvalidConflictPolicy
+}
+
+// partMimeType is the content type of an uploaded part. Clients that do not
+// know the type send application/octet-stream or nothing; the extension of
+// the file name says more then.
+func partMimeType(header *multipart.FileHeader) string {
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(filepath.Ext(header.Filename)); byExt != "" {
+			return byExt
+		}
+	}
+	return mimeType
+}
+
+// storeUpload saves an upload into dest, applying the conflict policy when an
+// item with the same name exists there. Replacing keeps the existing file's
+// ID and shares and adds a version when versioning is enabled. Skipping
+// returns the existing file.
+func storeUpload(db *gorm.DB, userID uint, up upload, dest destination, policy string) (models.File, string, error) {
+	name, existing, err := resolveFileName(db, userID, dest, up.Name, 0, policy)
+	if err != nil {
+		return models.File{}, "", err
+	}
+
+	if existing != nil {
+		if policy == conflictSkip {
+			return *existing, uploadSkipped, nil
+		}
+		file := *existing
+		if err := replaceContent(db, userID, &file, up.Content, up.Size, up.MimeType, ""); err != nil {
+			return file, "", err
+		}
+		return file, uploadReplaced, nil
+	}
+
+	if err := checkDriveQuota(db, dest.driveID, up.Size); err != nil {
+		return models.File{}, "", err
+	}
+
+	root := storageRoot(userID, dest.driveID)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return models.File{}, "", err
+	}
+	blob, err := newBlobPath(root, userID, name)
+	if err != nil {
+		return models.File{}, "", err
+	}
+	size, checksum, err := writeFileContent(blob, up.Content)
+	if err != nil {
+		return models.File{}, "", err
+	}
+
+	file := models.File{
+		Name:         name,
+		OriginalName: up.Name,
+		FolderID:     dest.folderID(),
+		DriveID:      dest.driveID,
+		UserID:       userID,
+		FilePath:     blob,
+		Size:         size,
+		MimeType:     up.MimeType,
+		Checksum:     checksum,
+	}
+	if err := db.Create(&file).Error; err != nil {
+		os.Remove(blob)
+		return file, "", err
+	}
+
+	recordFileChange(db, &file, "create")
+
+	outcome := uploadCreated
+	if name != up.Name {
+		outcome = uploadRenamed
+	}
+	return file, outcome, nil
+}
+
+// replaceContent writes new content into an existing file, as a new version
+// when versioning is enabled on the file or by its policy, and records the
+// update. Old versions the policy no longer keeps are pruned.
+func replaceContent(db *gorm.DB, userID uint, file *models.File, src io.Reader, size int64, mimeType, comment string) error {
+	if err := checkDriveQuota(db, file.DriveID, size-file.Size); err != nil {
+		return err
+	}
+
+	policy := versioning.PolicyFor(db, file)
+	if !file.VersioningEnabled && policy != nil && policy.Enabled {
+		if err := enableVersioning(db, file, userID); err != nil {
+			return err
+		}
+	}
+
+	if file.VersioningEnabled {
+		if mimeType != "" {
+			file.MimeType = mimeType
+		}
+		if _, err := storeNewVersion(db, file, src, userID, comment); err != nil {
+			return err
+		}
+		pruneVersions(db, file, policy)
+	} else {
+		written, checksum, err := writeFileContent(file.FilePath, src)
+		if err != nil {
+			return err
+		}
+
+		file.Size = written
+		file.Checksum = checksum
+		if mimeType != "" {
+			file.MimeType = mimeType
+		}
+		if err := db.Save(file).Error; err != nil {
+			return err
+		}
+	}
+
+	recordFileChange(db, file, "update")
+	return nil
+}
+
+// newBlobPath returns a path in dir no other blob uses. The key is random, so
+// files with the same name in different folders never share content.
+func newBlobPath(dir string, userID uint, name string) (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%d_%s%s", userID, hex.EncodeToString(key), filepath.Ext(name))), nil
+}
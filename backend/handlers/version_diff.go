@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/config"
+	"a-drive-backend/models"
+	"a-drive-backend/textdiff"
+	"a-drive-backend/versioning"
+)
+
+// Diff modes
+const (
+	diffUnified    = "unified"
+	diffSideBySide = "side_by_side"
+)
+
+var (
+	errBinaryContent = errors.New("binary files cannot be compared")
+	errDiffTooLarge  = errors.New("version too large to compare")
+)
+
+// textMimeTypes are non text/* types whose content is text
+var textMimeTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/yaml":       true,
+	"application/x-yaml":     true,
+	"application/toml":       true,
+	"application/javascript": true,
+	"application/x-sh":       true,
+	"application/sql":        true,
+}
+
+// textExtensions identify text files uploaded without a useful MIME type
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".json": true, ".yaml": true, ".yml": true,
+	".xml": true, ".csv": true, ".ini": true, ".conf": true, ".cfg": true,
+	".toml": true, ".env": true, ".log": true, ".properties": true,
+	".html": true, ".css": true, ".js": true, ".ts": true, ".go": true,
+	".py": true, ".sh": true, ".sql": true,
+}
+
+// DiffSide identifies one side of a version comparison
+type DiffSide struct {
+	VersionID *uint  `json:"version_id"` // nil for the current content of an unversioned file
+	Version   int    `json:"version"`
+	Current   bool   `json:"current"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+	open      func() (io.ReadCloser, error)
+}
+
+// DiffVersions compares two versions of a text file, or a version with the
+// current content. The unified mode returns a unified diff and the line
+// diff; the side_by_side mode returns paired rows and normalizes the
+// formatting of JSON and YAML files first.
+func DiffVersions(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	mode := c.DefaultQuery("mode", diffUnified)
+	if mode != diffUnified && mode != diffSideBySide {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be unified or side_by_side"})
+		return
+	}
+	context, err := strconv.Atoi(c.DefaultQuery("context", "3"))
+	if err != nil || context < 0 || context > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "context must be between 0 and 100"})
+		return
+	}
+	if c.Query("from") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is required"})
+		return
+	}
+
+	from, ok := findDiffSide(c, db, &file, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := findDiffSide(c, db, &file, c.DefaultQuery("to", "current"))
+	if !ok {
+		return
+	}
+
+	limit := config.Load().DiffMaxSize
+	if from.Size > limit || to.Size > limit {
+		respondDiffReadError(c, errDiffTooLarge, limit)
+		return
+	}
+	oldText, err := readDiffText(from, &file, limit)
+	if err != nil {
+		respondDiffReadError(c, err, limit)
+		return
+	}
+	newText, err := readDiffText(to, &file, limit)
+	if err != nil {
+		respondDiffReadError(c, err, limit)
+		return
+	}
+
+	respondDiff(c, &file, mode, context, from, to, oldText, newText)
+}
+
+func respondDiffReadError(c *gin.Context, err error, limit int64) {
+	switch {
+	case errors.Is(err, errDiffTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Versions larger than %d bytes cannot be compared", limit)})
+	case errors.Is(err, errBinaryContent):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Binary files cannot be compared"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read version content"})
+	}
+}
+
+func respondDiff(c *gin.Context, file *models.File, mode string, context int, from, to DiffSide, oldText, newText string) {
+	result := gin.H{"file": file, "from": from, "to": to, "mode": mode}
+
+	if mode == diffUnified {
+		lines := textdiff.Lines(textdiff.SplitLines(oldText), textdiff.SplitLines(newText))
+		result["identical"] = textdiff.Identical(lines)
+		result["unified"] = textdiff.Unified(lines, diffLabel(file, from), diffLabel(file, to), context)
+		result["lines"] = lines
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	format := structuredFormat(file)
+	if format != "" {
+		var err error
+		if oldText, err = normalizeStructured(format, oldText); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Version %d is not valid %s: %v", from.Version, strings.ToUpper(format), err)})
+			return
+		}
+		if newText, err = normalizeStructured(format, newText); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Version %d is not valid %s: %v", to.Version, strings.ToUpper(format), err)})
+			return
+		}
+	} else {
+		format = "text"
+	}
+
+	lines := textdiff.Lines(textdiff.SplitLines(oldText), textdiff.SplitLines(newText))
+	result["identical"] = textdiff.Identical(lines)
+	result["format"] = format
+	result["rows"] = textdiff.SideBySide(lines)
+	c.JSON(http.StatusOK, result)
+}
+
+// findDiffSide resolves a version ID, or "current", of the file, writing the
+// error response itself
+func findDiffSide(c *gin.Context, db *gorm.DB, file *models.File, ref string) (DiffSide, bool) {
+	if ref == "current" {
+		side := DiffSide{Version: file.CurrentVersion, Current: true, Size: file.Size, Checksum: file.Checksum}
+		side.open = func() (io.ReadCloser, error) { return os.Open(file.FilePath) }
+		var version models.FileVersion
+		if file.VersioningEnabled && db.Where("file_id = ? AND version = ?", file.ID, file.CurrentVersion).First(&version).Error == nil {
+			side.VersionID = &version.ID
+		}
+		return side, true
+	}
+
+	versionID, err := strconv.ParseUint(ref, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be version IDs or current"})
+		return DiffSide{}, false
+	}
+	var version models.FileVersion
+	if err := db.Where("id = ? AND file_id = ?", versionID, file.ID).First(&version).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return DiffSide{}, false
+	}
+	return DiffSide{
+		VersionID: &version.ID,
+		Version:   version.Version,
+		Current:   version.Version == file.CurrentVersion,
+		Size:      version.Size,
+		Checksum:  version.Checksum,
+		open:      func() (io.ReadCloser, error) { return versioning.Open(db, &version) },
+	}, true
+}
+
+// readDiffText reads a version as text. Files are text when their MIME type
+// or extension say so, or their content sniffs as text, and they must be
+// valid UTF-8 without NUL bytes.
+func readDiffText(side DiffSide, file *models.File, limit int64) (string, error) {
+	f, err := side.open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(content)) > limit {
+		return "", errDiffTooLarge
+	}
+
+	if !isTextFile(file, content) || bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		return "", errBinaryContent
+	}
+	return string(content), nil
+}
+
+func isTextFile(file *models.File, content []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(file.MimeType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"), textMimeTypes[mediaType],
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	case textExtensions[strings.ToLower(filepath.Ext(file.Name))]:
+		return true
+	}
+	return strings.HasPrefix(http.DetectContentType(content), "text/")
+}
+
+// structuredFormat returns "json" or "yaml" for files whose formatting can be
+// normalized, and "" otherwise
+func structuredFormat(file *models.File) string {
+	mediaType, _, _ := mime.ParseMediaType(file.MimeType)
+	ext := strings.ToLower(filepath.Ext(file.Name))
+	switch {
+	case ext == ".json", mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case ext == ".yaml", ext == ".yml", strings.Contains(mediaType, "yaml"):
+		return "yaml"
+	}
+	return ""
+}
+
+// normalizeStructured re-encodes JSON or YAML with sorted keys and uniform
+// indentation, so that the diff only shows changes of the data
+func normalizeStructured(format, text string) (string, error) {
+	var out bytes.Buffer
+	if format == "json" {
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return "", err
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			return "", errors.New("unexpected data after the top-level value")
+		}
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(value); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}
+
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	for {
+		var value interface{}
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := enc.Encode(value); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// diffLabel names one side in the unified diff header
+func diffLabel(file *models.File, side DiffSide) string {
+	if side.Current {
+		return fmt.Sprintf("%s (current, v%d)", file.Name, side.Version)
+	}
+	return fmt.Sprintf("%s (v%d)", file.Name, side.Version)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+	"a-drive-backend/versioning"
+)
+
+// GetFileVersionStats compares the size of a file's old versions with the
+// bytes stored for them
+func GetFileVersionStats(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	stats, err := versioning.StatsFor(db, db.Model(&models.File{}).Select("id").Where("id = ?", file.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate version statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"file_id": file.ID, "stats": stats})
+}
+
+// GetVersioningStats does the same for all files of the personal space, or
+// of a team drive
+func GetVersioningStats(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	driveID, err := parseDriveID(c.Query("drive_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid drive ID"})
+		return
+	}
+	if !authorizeSpace(c, db, driveID, authz.FilesRead) {
+		return
+	}
+
+	fileIDs := personalOrDrive(db.Model(&models.File{}).Select("id"), userID, driveID)
+	stats, err := versioning.StatsFor(db, fileIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate version statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
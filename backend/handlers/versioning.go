@@ -2,17 +2,24 @@ package handlers
 
 import (
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/config"
+	"a-drive-backend/logging"
 	"a-drive-backend/models"
+	"a-drive-backend/versioning"
 )
 
 func EnableVersioning(c *gin.Context) {
@@ -21,9 +28,8 @@ func EnableVersioning(c *gin.Context) {
 	
 	fileID := c.Param("id")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
@@ -32,32 +38,8 @@ func EnableVersioning(c *gin.Context) {
 		return
 	}
 	
-	// Create initial version entry
-	checksum, err := calculateFileChecksum(file.FilePath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate file checksum"})
-		return
-	}
-	
-	version := models.FileVersion{
-		FileID:    file.ID,
-		Version:   1,
-		FilePath:  file.FilePath,
-		Size:      file.Size,
-		Checksum:  checksum,
-		Comment:   "Initial version",
-		CreatedBy: userID,
-	}
-	
-	if err := db.Create(&version).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create version record"})
-		return
-	}
-	
-	// Enable versioning on the file
-	file.VersioningEnabled = true
-	if err := db.Save(&file).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable versioning"})
+	if err := enableVersioning(db, &file, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	
@@ -66,13 +48,11 @@ func EnableVersioning(c *gin.Context) {
 
 func DisableVersioning(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
@@ -109,13 +89,11 @@ func DisableVersioning(c *gin.Context) {
 
 func GetFileVersions(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesRead)
+	if !ok {
 		return
 	}
 	
@@ -146,9 +124,8 @@ func CreateNewVersion(c *gin.Context) {
 	fileID := c.Param("id")
 	comment := c.PostForm("comment")
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
@@ -163,6 +140,11 @@ func CreateNewVersion(c *gin.Context) {
 		return
 	}
 	
+	if err := checkDriveQuota(db, file.DriveID, uploadedFile.Size-file.Size); err != nil {
+		respondQuotaError(c, err)
+		return
+	}
+	
 	// Open uploaded file
 	src, err := uploadedFile.Open()
 	if err != nil {
@@ -171,79 +153,15 @@ func CreateNewVersion(c *gin.Context) {
 	}
 	defer src.Close()
 	
-	// Create new version file path
-	newVersion := file.CurrentVersion + 1
-	fileExt := filepath.Ext(file.FilePath)
-	baseName := file.FilePath[:len(file.FilePath)-len(fileExt)]
-	
-	// Copy current file to versioned location
-	oldVersionPath := fmt.Sprintf("%s_v%d%s", baseName, file.CurrentVersion, fileExt)
-	if err := copyFile(file.FilePath, oldVersionPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to backup current version"})
-		return
-	}
-	
-	// Create version record for the old file
-	oldChecksum, _ := calculateFileChecksum(file.FilePath)
-	oldVersion := models.FileVersion{
-		FileID:    file.ID,
-		Version:   file.CurrentVersion,
-		FilePath:  oldVersionPath,
-		Size:      file.Size,
-		Checksum:  oldChecksum,
-		Comment:   "Previous version",
-		CreatedBy: userID,
-	}
-	
-	if err := db.Create(&oldVersion).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create old version record"})
-		return
-	}
-	
-	// Save new file
-	dst, err := os.Create(file.FilePath)
+	newVersionRecord, err := storeNewVersion(db, &file, src, userID, comment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create new file"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	defer dst.Close()
 	
-	size, err := io.Copy(dst, src)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save new file"})
-		return
-	}
+	pruneVersions(db, &file, versioning.PolicyFor(db, &file))
 	
-	// Calculate new checksum
-	newChecksum, err := calculateFileChecksum(file.FilePath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate new file checksum"})
-		return
-	}
-	
-	// Create new version record
-	newVersionRecord := models.FileVersion{
-		FileID:    file.ID,
-		Version:   newVersion,
-		FilePath:  file.FilePath,
-		Size:      size,
-		Checksum:  newChecksum,
-		Comment:   comment,
-		CreatedBy: userID,
-	}
-	
-	if err := db.Create(&newVersionRecord).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create new version record"})
-		return
-	}
-	
-	// Update file record
-	file.CurrentVersion = newVersion
-	file.Size = size
-	if err := db.Save(&file).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update file record"})
-		return
-	}
+	recordFileChange(db, &file, "update")
 	
 	c.JSON(http.StatusOK, gin.H{
 		"message": "New version created successfully",
@@ -264,9 +182,8 @@ func RestoreVersion(c *gin.Context) {
 		return
 	}
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesWrite)
+	if !ok {
 		return
 	}
 	
@@ -281,28 +198,45 @@ func RestoreVersion(c *gin.Context) {
 		return
 	}
 	
-	// Copy version file to current location
-	if err := copyFile(version.FilePath, file.FilePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore version"})
+	if version.Version == file.CurrentVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Version is already the current version"})
 		return
 	}
 	
-	// Update file record
-	file.Size = version.Size
-	if err := db.Save(&file).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update file record"})
+	if err := checkDriveQuota(db, file.DriveID, version.Size-file.Size); err != nil {
+		respondQuotaError(c, err)
 		return
 	}
 	
+	src, err := versioning.Open(db, &version)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open version"})
+		return
+	}
+	defer src.Close()
+	
+	// The restored content becomes a new version, so the replaced one is kept
+	comment := fmt.Sprintf("Restored from version %d", version.Version)
+	newVersionRecord, err := storeNewVersion(db, &file, src, userID, comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	pruneVersions(db, &file, versioning.PolicyFor(db, &file))
+	
+	recordFileChange(db, &file, "update")
+	
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Version restored successfully",
 		"restored_version": version.Version,
+		"version": newVersionRecord,
+		"file": file,
 	})
 }
 
 func DownloadVersion(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
-	userID := c.MustGet("user_id").(uint)
 	
 	fileID := c.Param("id")
 	versionIDStr := c.Param("version_id")
@@ -313,9 +247,8 @@ func DownloadVersion(c *gin.Context) {
 		return
 	}
 	
-	var file models.File
-	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	file, ok := loadFile(c, db, fileID, authz.FilesRead)
+	if !ok {
 		return
 	}
 	
@@ -325,10 +258,237 @@ func DownloadVersion(c *gin.Context) {
 		return
 	}
 	
-	c.FileAttachment(version.FilePath, fmt.Sprintf("%s_v%d%s", 
+	content, err := versioning.Open(db, &version)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open version"})
+		return
+	}
+	defer content.Close()
+	
+	name := fmt.Sprintf("%s_v%d%s", 
 		file.Name[:len(file.Name)-len(filepath.Ext(file.Name))], 
 		version.Version, 
-		filepath.Ext(file.Name)))
+		filepath.Ext(file.Name))
+	contentType := file.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, version.Size, contentType, content, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, name),
+	})
+}
+
+// UpdateVersionRequest edits the metadata of a version. Omitted fields are
+// left unchanged.
+type UpdateVersionRequest struct {
+	Comment *string `json:"comment" binding:"omitempty,max=1000"`
+	Label   *string `json:"label" binding:"omitempty,max=100"`
+	Pinned  *bool   `json:"pinned"`
+}
+
+// UpdateVersion edits the comment and label of a version and pins or unpins
+// it. Pinned versions are never removed by pruning.
+func UpdateVersion(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	
+	var req UpdateVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+	version, ok := loadVersion(c, db, &file)
+	if !ok {
+		return
+	}
+	
+	if req.Comment != nil {
+		version.Comment = *req.Comment
+	}
+	if req.Label != nil {
+		version.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.Pinned != nil {
+		version.Pinned = *req.Pinned
+	}
+	if err := db.Save(&version).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update version"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{"version": version})
+}
+
+// DeleteVersion removes an old version and its content. The current version
+// cannot be deleted, and pinned versions must be unpinned first.
+func DeleteVersion(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+	version, ok := loadVersion(c, db, &file)
+	if !ok {
+		return
+	}
+	
+	if version.Version == file.CurrentVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The current version cannot be deleted"})
+		return
+	}
+	if version.Pinned {
+		c.JSON(http.StatusConflict, gin.H{"error": "Version is pinned, unpin it first"})
+		return
+	}
+	
+	if err := versioning.Delete(db, &file, &version); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete version"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{"message": "Version deleted successfully"})
+}
+
+// loadVersion finds the version named by the :version_id parameter among the
+// versions of file, writing the error response itself
+func loadVersion(c *gin.Context, db *gorm.DB, file *models.File) (models.FileVersion, bool) {
+	var version models.FileVersion
+	versionID, err := strconv.ParseUint(c.Param("version_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version ID"})
+		return version, false
+	}
+	if err := db.Where("id = ? AND file_id = ?", versionID, file.ID).First(&version).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return version, false
+	}
+	return version, true
+}
+
+// enableVersioning turns on versioning for a file, recording its content as
+// the first version. Returned errors are user-facing.
+func enableVersioning(db *gorm.DB, file *models.File, userID uint) error {
+	checksum, err := calculateFileChecksum(file.FilePath)
+	if err != nil {
+		return errors.New("Failed to calculate file checksum")
+	}
+	
+	version := models.FileVersion{
+		FileID:    file.ID,
+		Version:   1,
+		FilePath:  file.FilePath,
+		Size:      file.Size,
+		Checksum:  checksum,
+		Comment:   "Initial version",
+		CreatedBy: userID,
+	}
+	if err := db.Create(&version).Error; err != nil {
+		return errors.New("Failed to create version record")
+	}
+	
+	file.VersioningEnabled = true
+	file.CurrentVersion = 1
+	if err := db.Save(file).Error; err != nil {
+		return errors.New("Failed to enable versioning")
+	}
+	return nil
+}
+
+// storeNewVersion moves the current content of a versioned file to its own
+// blob, writes src in its place and records the new version. Returned errors
+// are user-facing.
+func storeNewVersion(db *gorm.DB, file *models.File, src io.Reader, userID uint, comment string) (*models.FileVersion, error) {
+	// Find or create the record of the current version
+	var oldVersion models.FileVersion
+	if err := db.Where("file_id = ? AND version = ?", file.ID, file.CurrentVersion).First(&oldVersion).Error; err != nil {
+		oldChecksum, _ := calculateFileChecksum(file.FilePath)
+		oldVersion = models.FileVersion{
+			FileID:    file.ID,
+			Version:   file.CurrentVersion,
+			FilePath:  file.FilePath,
+			Size:      file.Size,
+			Checksum:  oldChecksum,
+			Comment:   "Previous version",
+			CreatedBy: userID,
+		}
+		if err := db.Create(&oldVersion).Error; err != nil {
+			return nil, errors.New("Failed to create old version record")
+		}
+	}
+	
+	// Keep the current content for that record. Large files go to the chunk
+	// store, where versions share the chunks an edit did not touch.
+	if file.Size >= config.Load().VersionChunkMinSize {
+		current, err := os.Open(file.FilePath)
+		if err != nil {
+			return nil, errors.New("Failed to backup current version")
+		}
+		err = versioning.StoreChunked(db, &oldVersion, current)
+		current.Close()
+		if err != nil {
+			return nil, errors.New("Failed to backup current version")
+		}
+	} else {
+		fileExt := filepath.Ext(file.FilePath)
+		baseName := file.FilePath[:len(file.FilePath)-len(fileExt)]
+		oldVersionPath := fmt.Sprintf("%s_v%d%s", baseName, file.CurrentVersion, fileExt)
+		if err := copyFile(file.FilePath, oldVersionPath); err != nil {
+			return nil, errors.New("Failed to backup current version")
+		}
+		oldVersion.FilePath = oldVersionPath
+		if err := db.Save(&oldVersion).Error; err != nil {
+			os.Remove(oldVersionPath)
+			return nil, errors.New("Failed to update old version record")
+		}
+	}
+	
+	// Save new file
+	size, checksum, err := writeFileContent(file.FilePath, src)
+	if err != nil {
+		return nil, errors.New("Failed to save new file")
+	}
+	
+	// Create new version record
+	newVersionRecord := models.FileVersion{
+		FileID:    file.ID,
+		Version:   file.CurrentVersion + 1,
+		FilePath:  file.FilePath,
+		Size:      size,
+		Checksum:  checksum,
+		Comment:   comment,
+		CreatedBy: userID,
+	}
+	
+	if err := db.Create(&newVersionRecord).Error; err != nil {
+		return nil, errors.New("Failed to create new version record")
+	}
+	
+	// Update file record
+	file.CurrentVersion = newVersionRecord.Version
+	file.Size = size
+	file.Checksum = checksum
+	if err := db.Save(file).Error; err != nil {
+		return nil, errors.New("Failed to update file record")
+	}
+	
+	return &newVersionRecord, nil
+}
+
+// pruneVersions deletes the old versions of a file that its policy no longer
+// keeps. The new content is stored by then, so a failure does not fail the
+// request; it is logged, and the periodic pruning tries again.
+func pruneVersions(db *gorm.DB, file *models.File, policy *models.VersioningPolicy) {
+	if policy == nil {
+		return
+	}
+	if _, err := versioning.Prune(db, file, policy, time.Now()); err != nil {
+		logging.FromContext(db.Statement.Context).Error("Failed to prune old versions", "file_id", file.ID, "error", err)
+	}
 }
 
 // Helper functions
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/models"
+	"a-drive-backend/versioning"
+)
+
+// VersioningPolicyRequest sets a folder or user versioning policy. Zero keep
+// rules keep every old version.
+type VersioningPolicyRequest struct {
+	Enabled         bool  `json:"enabled"`
+	KeepLast        int   `json:"keep_last" binding:"min=0"`
+	KeepDailyDays   int   `json:"keep_daily_days" binding:"min=0"`
+	KeepWeeklyWeeks int   `json:"keep_weekly_weeks" binding:"min=0"`
+	MaxTotalBytes   int64 `json:"max_total_bytes" binding:"min=0"`
+}
+
+// GetUserVersioningPolicy returns the user's default policy for personal files
+func GetUserVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	if !authorizeSpace(c, db, nil, authz.FilesRead) {
+		return
+	}
+
+	var policy models.VersioningPolicy
+	if err := db.Where("user_id = ?", userID).First(&policy).Error; err != nil {
+		c.JSON(http.StatusOK, gin.H{"policy": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// SetUserVersioningPolicy creates or replaces the user's default policy
+func SetUserVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	if !authorizeSpace(c, db, nil, authz.FilesWrite) {
+		return
+	}
+	savePolicy(c, db, "user_id", userID)
+}
+
+// DeleteUserVersioningPolicy removes the user's default policy
+func DeleteUserVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("user_id").(uint)
+
+	if !authorizeSpace(c, db, nil, authz.FilesWrite) {
+		return
+	}
+	deletePolicy(c, db, "user_id", userID)
+}
+
+// GetFolderVersioningPolicy returns the policy set on a folder itself
+func GetFolderVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	var policy models.VersioningPolicy
+	if err := db.Where("folder_id = ?", folder.ID).First(&policy).Error; err != nil {
+		c.JSON(http.StatusOK, gin.H{"policy": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// SetFolderVersioningPolicy creates or replaces the policy of a folder. It
+// applies to the whole subtree unless a subfolder has its own.
+func SetFolderVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+	savePolicy(c, db, "folder_id", folder.ID)
+}
+
+// DeleteFolderVersioningPolicy removes the policy of a folder
+func DeleteFolderVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	folder, ok := loadFolder(c, db, c.Param("id"), authz.FilesWrite)
+	if !ok {
+		return
+	}
+	deletePolicy(c, db, "folder_id", folder.ID)
+}
+
+// GetEffectiveVersioningPolicy returns the policy that applies to a file
+func GetEffectiveVersioningPolicy(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	file, ok := loadFile(c, db, c.Param("id"), authz.FilesRead)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versioning_enabled": file.VersioningEnabled,
+		"policy":             versioning.PolicyFor(db, &file),
+	})
+}
+
+// savePolicy binds the request and upserts the policy owned by column = id
+func savePolicy(c *gin.Context, db *gorm.DB, column string, id uint) {
+	var req VersioningPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var policy models.VersioningPolicy
+	if err := db.Where(column+" = ?", id).First(&policy).Error; err != nil {
+		if column == "folder_id" {
+			policy.FolderID = &id
+		} else {
+			policy.UserID = &id
+		}
+	}
+	policy.Enabled = req.Enabled
+	policy.KeepLast = req.KeepLast
+	policy.KeepDailyDays = req.KeepDailyDays
+	policy.KeepWeeklyWeeks = req.KeepWeeklyWeeks
+	policy.MaxTotalBytes = req.MaxTotalBytes
+
+	if err := db.Save(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save versioning policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+func deletePolicy(c *gin.Context, db *gorm.DB, column string, id uint) {
+	result := db.Where(column+" = ?", id).Delete(&models.VersioningPolicy{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete versioning policy"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No versioning policy set"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Versioning policy deleted"})
+}
@@ -0,0 +1,106 @@
+// Package health checks whether the server can take requests: that the
+// database answers and that the storage directory is writable with enough
+// free space. It also tracks whether the server is shutting down.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Check results
+const (
+	OK      = "ok"
+	Fail    = "fail"
+	Skipped = "skipped"
+)
+
+// databaseTimeout bounds the database ping so that probes answer in time
+const databaseTimeout = 2 * time.Second
+
+// errFreeSpaceUnknown is returned where free space cannot be looked up
+var errFreeSpaceUnknown = errors.New("free space cannot be determined on this platform")
+
+// Check is the result of one readiness check. Error is meant for operators
+// and does not include paths.
+type Check struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	FreeBytes *int64 `json:"free_bytes,omitempty"`
+}
+
+var shuttingDown atomic.Bool
+
+// BeginShutdown makes the server report itself as not ready, so that load
+// balancers stop sending it requests while in-flight ones finish
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether BeginShutdown was called
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Database pings the database
+func Database(ctx context.Context, db *gorm.DB) (Check, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return Check{Status: Fail, Error: "database unavailable"}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, databaseTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return Check{Status: Fail, Error: "database unavailable"}, err
+	}
+	return Check{Status: OK}, nil
+}
+
+// Storage creates and removes a file in the storage directory
+func Storage(dir string) (Check, error) {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return Check{Status: Fail, Error: "storage directory is not writable"}, err
+	}
+	name := f.Name()
+	_, err = f.WriteString("ok")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	if err != nil {
+		return Check{Status: Fail, Error: "storage directory is not writable"}, err
+	}
+	return Check{Status: OK}, nil
+}
+
+// DiskSpace fails when the file system of the storage directory has less than
+// minFree bytes available. A minFree of 0 skips the check.
+func DiskSpace(dir string, minFree int64) (Check, error) {
+	if minFree <= 0 {
+		return Check{Status: Skipped}, nil
+	}
+	free, err := freeSpace(dir)
+	if errors.Is(err, errFreeSpaceUnknown) {
+		return Check{Status: Skipped}, nil
+	}
+	if err != nil {
+		return Check{Status: Fail, Error: "free space could not be read"}, err
+	}
+	if free < minFree {
+		return Check{
+			Status:    Fail,
+			Error:     fmt.Sprintf("%d bytes free, at least %d required", free, minFree),
+			FreeBytes: &free,
+		}, nil
+	}
+	return Check{Status: OK, FreeBytes: &free}, nil
+}
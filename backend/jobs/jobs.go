@@ -0,0 +1,82 @@
+// Package jobs runs background maintenance tasks at fixed intervals.
+package jobs
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"a-drive-backend/maintenance"
+	"a-drive-backend/metrics"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+// Scheduler runs every registered job in its own goroutine until stopped
+type Scheduler struct {
+	jobs []job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers a job to run once per interval. A zero or negative
+// interval disables the job. Jobs must be registered before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func() error) {
+	if interval <= 0 {
+		slog.Info("Job disabled", "job", name)
+		return
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start launches the jobs. Each one first runs after one interval.
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop waits for running jobs to finish and ends the schedule
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if maintenance.Enabled() {
+				slog.Info("Job skipped in maintenance mode", "job", j.name)
+				continue
+			}
+			start := time.Now()
+			err := j.run()
+			metrics.JobDuration.Observe(time.Since(start).Seconds(), j.name)
+			if err != nil {
+				metrics.JobRuns.Inc(j.name, "failure")
+				slog.Error("Job failed", "job", j.name, "error", err)
+				continue
+			}
+			metrics.JobRuns.Inc(j.name, "success")
+			metrics.JobLastSuccess.Set(float64(time.Now().Unix()), j.name)
+			slog.Info("Job finished", "job", j.name, "duration_ms", time.Since(start).Milliseconds())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package logging sets up structured logs with log/slog and carries the
+// logger of a request, with its request and user IDs, in its context.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Redacted replaces the values of secrets in logs
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute and query parameter names whose values are
+// never logged
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"password":      true,
+	"session":       true,
+	"session_token": true,
+	"token":         true,
+	"access_token":  true,
+}
+
+// Setup makes a JSON, or with format "text" a text, handler at the given
+// level the default logger. Output of the standard log package goes through
+// it too.
+func Setup(level, format string) {
+	slog.SetDefault(slog.New(newHandler(os.Stdout, ParseLevel(level), format)))
+}
+
+func newHandler(w io.Writer, level slog.Level, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	if format == "text" {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// ParseLevel reads debug, info, warn or error, defaulting to info
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// RedactQuery returns a raw query string with the values of sensitive
+// parameters, such as share sessions, replaced
+func RedactQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil && sensitiveKeys[strings.ToLower(name)] {
+			params[i] = key + "=" + Redacted
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+type contextKey struct{}
+
+// WithLogger returns a context carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of a context, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// With returns a context whose logger adds the given attributes
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// Fatal logs an error and exits
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// RedactHeaders returns request or response headers for logging, with the
+// values of credentials replaced
+func RedactHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for key, values := range header {
+		if sensitiveKeys[strings.ToLower(key)] {
+			out[key] = Redacted
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -9,35 +16,86 @@ import (
 
 	"a-drive-backend/config"
 	"a-drive-backend/database"
+	"a-drive-backend/health"
+	"a-drive-backend/jobs"
+	"a-drive-backend/logging"
+	"a-drive-backend/maintenance"
 	"a-drive-backend/middleware"
 	"a-drive-backend/routes"
+	"a-drive-backend/sharing"
+	"a-drive-backend/versioning"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
+	envErr := godotenv.Load()
 
 	cfg := config.Load()
-	db := database.Init(cfg.DatabasePath)
+	logging.Setup(cfg.LogLevel, cfg.LogFormat)
+	if envErr != nil {
+		slog.Info("No .env file found")
+	}
+
+	db := database.Init(cfg)
+
+	if cfg.MaintenanceMode {
+		maintenance.Set(true, cfg.MaintenanceMessage)
+		slog.Warn("Starting in maintenance mode")
+	}
+
+	scheduler := jobs.New()
+	scheduler.Every("prune-versions", cfg.VersionPruneInterval, func() error {
+		return versioning.PruneAll(db)
+	})
+	scheduler.Every("collect-chunks", cfg.VersionPruneInterval, func() error {
+		return versioning.CollectChunks(db)
+	})
+	scheduler.Every("expire-shares", cfg.ShareExpiryInterval, func() error {
+		return sharing.ExpireAll(db)
+	})
+	scheduler.Start()
+
+	r := gin.New()
+	// Client addresses key the share password throttle and share analytics,
+	// so forwarding headers count only when a trusted proxy set them
+	if err := r.SetTrustedProxies(cfg.GetTrustedProxies()); err != nil {
+		logging.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+	}
 
-	r := gin.Default()
+	// First, so that it also sees requests the middleware below rejects
+	if cfg.MetricsEnabled {
+		r.Use(middleware.MetricsMiddleware())
+	}
+	r.Use(middleware.RequestIDMiddleware(), middleware.LoggerMiddleware(), middleware.RecoveryMiddleware())
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.GetCORSOrigins(),
 		AllowMethods:     cfg.GetCORSMethods(),
 		AllowHeaders:     cfg.GetCORSHeaders(),
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
 	r.Use(middleware.DatabaseMiddleware(db))
+	r.Use(middleware.MaintenanceMiddleware())
+
+	// The spec is built from the registered routes on first use
+	spec := routes.NewOpenAPISpec(r)
+	if cfg.OpenAPIValidation {
+		r.Use(spec.ValidationMiddleware())
+	}
 
-	// Health check endpoint
+	// Health check endpoint, kept for existing monitors; it does not check
+	// anything, see /readyz
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "OK", "message": "A-Drive is running"})
 	})
 
+	// Liveness and readiness probes
+	routes.SetupHealthRoutes(r)
+
+	// Prometheus metrics, protected by METRICS_TOKEN when set
+	routes.SetupMetricsRoutes(r)
+
 	// CORS info endpoint (public)
 	r.GET("/cors", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -51,6 +109,7 @@ func main() {
 	// Public auth routes (no middleware)
 	authRoutes := r.Group("/api/auth")
 	routes.SetupAuthRoutes(authRoutes)
+	routes.SetupOpenAPIRoutes(r.Group("/api"), spec)
 
 	// Protected API routes (requires authentication)
 	apiRoutes := r.Group("/api")
@@ -62,17 +121,50 @@ func main() {
 	routes.SetupProtectedAuthRoutes(apiRoutes)
 	routes.SetupAnalyticsRoutes(apiRoutes)
 	routes.SetupSharingRoutes(apiRoutes)
+	routes.SetupChangesRoutes(apiRoutes)
+	routes.SetupGroupRoutes(apiRoutes)
+	routes.SetupDriveRoutes(apiRoutes)
+	routes.SetupNotificationRoutes(apiRoutes)
 
 	adminRoutes := r.Group("/api/admin")
 	adminRoutes.Use(middleware.AuthMiddleware())
-	adminRoutes.Use(middleware.AdminMiddleware())
 	routes.SetupAdminRoutes(adminRoutes)
 
 	// Public sharing routes (no authentication required)
 	routes.SetupPublicSharingRoutes(r)
 
-	log.Printf("Server starting on port %s", cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	for _, problem := range spec.Problems() {
+		slog.Warn("OpenAPI spec problem", "problem", problem)
+	}
+
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+	go func() {
+		slog.Info("Server starting", "port", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Failed to start server", "error", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	// Stop being ready and give load balancers time to notice, then let
+	// in-flight requests and uploads finish
+	health.BeginShutdown()
+	slog.Info("Shutting down", "drain_delay", cfg.ShutdownDrainDelay.String(), "timeout", cfg.ShutdownTimeout.String())
+	time.Sleep(cfg.ShutdownDrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Requests still running at the shutdown timeout were cut off", "error", err)
+		srv.Close()
+	}
+
+	// Running jobs finish before the database is closed
+	scheduler.Stop()
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
 	}
+	slog.Info("Server stopped")
 }
\ No newline at end of file
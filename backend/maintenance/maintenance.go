@@ -0,0 +1,58 @@
+// Package maintenance holds the maintenance mode of the server. While it is
+// on, requests that change data are rejected and background jobs are paused,
+// so that backups and upgrades see a quiet database and storage directory.
+package maintenance
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMessage is shown when maintenance mode is turned on without one
+const DefaultMessage = "A-Drive is in maintenance mode; changes are disabled for now, try again later"
+
+// State is the current maintenance mode
+type State struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+var (
+	mu    sync.RWMutex
+	state State
+)
+
+// Get returns the current state
+func Get() State {
+	mu.RLock()
+	defer mu.RUnlock()
+	return state
+}
+
+// Enabled reports whether maintenance mode is on
+func Enabled() bool {
+	return Get().Enabled
+}
+
+// Set turns maintenance mode on or off and returns the new state. Turning it
+// on while it is on only changes the message.
+func Set(enabled bool, message string) State {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !enabled {
+		state = State{}
+		return state
+	}
+	if message == "" {
+		message = DefaultMessage
+	}
+	since := state.Since
+	if since == nil {
+		now := time.Now()
+		since = &now
+	}
+	state = State{Enabled: true, Message: message, Since: since}
+	return state
+}
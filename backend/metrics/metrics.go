@@ -0,0 +1,360 @@
+// Package metrics keeps counters, gauges and histograms and writes them in the
+// Prometheus text exposition format.
+//
+// Metrics are created once, usually as package variables, and registered in
+// the default registry that the /metrics endpoint writes.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector is a metric family that can write itself
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry holds metric families by name
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+func newRegistry() *Registry {
+	return &Registry{collectors: map[string]collector{}}
+}
+
+// Default is the registry the New functions register in
+var Default = newRegistry()
+
+// register adds a family and panics on a duplicate name, which is a
+// programming error
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.collectors[c.name()]; ok {
+		panic("metrics: duplicate metric " + c.name())
+	}
+	r.collectors[c.name()] = c
+}
+
+// Write writes every family in the text exposition format, sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		families = append(families, c)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+	for _, c := range families {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// family is what every metric type shares: its name, help, label names and
+// one series per combination of label values
+type family struct {
+	fullName string
+	help     string
+	kind     string
+	labels   []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64 // histograms only, not cumulative
+	count       uint64
+}
+
+// init sets up a family. Families without labels have their single series
+// from the start, so they are written as 0 before anything happens.
+func (f *family) init(name, help, kind string, labels []string, buckets int) {
+	f.fullName, f.help, f.kind, f.labels = name, help, kind, labels
+	f.series = map[string]*series{}
+	if len(labels) == 0 {
+		f.get(nil, buckets)
+	}
+}
+
+func (f *family) name() string { return f.fullName }
+
+// get returns the series of the label values, creating it. f.mu must be
+// held.
+func (f *family) get(values []string, buckets int) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.fullName, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), values...)}
+		if buckets > 0 {
+			s.buckets = make([]uint64, buckets)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// sorted returns copies of the series ordered by label values
+func (f *family) sorted() []series {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]series, len(keys))
+	for i, key := range keys {
+		s := *f.series[key]
+		s.buckets = append([]uint64(nil), s.buckets...)
+		out[i] = s
+	}
+	return out
+}
+
+func (f *family) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.fullName, escapeHelp(f.help), f.fullName, f.kind)
+	return err
+}
+
+// Counter is a value that only goes up, such as a number of requests
+type Counter struct{ family }
+
+// NewCounter registers a counter with the given label names
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{}
+	c.init(name, help, "counter", labels, 0)
+	Default.register(c)
+	return c
+}
+
+// Add adds v, which must not be negative, to the series of the label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter " + c.fullName + " cannot decrease")
+	}
+	c.mu.Lock()
+	c.get(labelValues, 0).value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+func (c *Counter) write(w io.Writer) error {
+	return writeValues(w, &c.family)
+}
+
+// Gauge is a value that goes up and down, such as uploads in progress
+type Gauge struct{ family }
+
+// NewGauge registers a gauge with the given label names
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{}
+	g.init(name, help, "gauge", labels, 0)
+	Default.register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues, 0).value = v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues, 0).value += v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+func (g *Gauge) write(w io.Writer) error {
+	return writeValues(w, &g.family)
+}
+
+// GaugeFunc is a gauge read when metrics are written, for values that are
+// cheaper to look up than to track, such as storage used
+type GaugeFunc struct {
+	family
+	read func() (map[string]float64, error)
+}
+
+// NewGaugeFunc registers a gauge with at most one label. read returns the
+// values by label value; without a label, use the key "".
+func NewGaugeFunc(name, help, label string, read func() (map[string]float64, error)) *GaugeFunc {
+	var labels []string
+	if label != "" {
+		labels = []string{label}
+	}
+	g := &GaugeFunc{read: read}
+	g.init(name, help, "gauge", labels, 0)
+	Default.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) error {
+	values, err := g.read()
+	if err != nil {
+		// Leave the family out rather than fail the whole scrape
+		return nil
+	}
+
+	g.mu.Lock()
+	g.series = map[string]*series{}
+	for labelValue, v := range values {
+		var labelValues []string
+		if len(g.labels) > 0 {
+			labelValues = []string{labelValue}
+		}
+		g.get(labelValues, 0).value = v
+	}
+	g.mu.Unlock()
+	return writeValues(w, &g.family)
+}
+
+// Histogram counts observations, such as request latencies, in buckets
+type Histogram struct {
+	family
+	upperBounds []float64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds,
+// which must be sorted, and label names
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{upperBounds: buckets}
+	h.init(name, help, "histogram", labels, len(buckets))
+	Default.register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+
+	h.mu.Lock()
+	s := h.get(labelValues, len(h.upperBounds))
+	if i < len(s.buckets) {
+		s.buckets[i]++
+	}
+	s.count++
+	s.value += v
+	h.mu.Unlock()
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	all := h.sorted()
+	if len(all) == 0 {
+		return nil
+	}
+	if err := h.header(w); err != nil {
+		return err
+	}
+	for _, s := range all {
+		var cumulative uint64
+		for i, bound := range h.upperBounds {
+			cumulative += s.buckets[i]
+			if err := writeSample(w, h.fullName+"_bucket", h.labels, s.labelValues, "le", formatFloat(bound), float64(cumulative)); err != nil {
+				return err
+			}
+		}
+		if err := writeSample(w, h.fullName+"_bucket", h.labels, s.labelValues, "le", "+Inf", float64(s.count)); err != nil {
+			return err
+		}
+		if err := writeSample(w, h.fullName+"_sum", h.labels, s.labelValues, "", "", s.value); err != nil {
+			return err
+		}
+		if err := writeSample(w, h.fullName+"_count", h.labels, s.labelValues, "", "", float64(s.count)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeValues writes a family of plain values. Families without series are
+// left out.
+func writeValues(w io.Writer, f *family) error {
+	all := f.sorted()
+	if len(all) == 0 {
+		return nil
+	}
+	if err := f.header(w); err != nil {
+		return err
+	}
+	for _, s := range all {
+		if err := writeSample(w, f.fullName, f.labels, s.labelValues, "", "", s.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSample writes one line, with an optional extra label such as le
+func writeSample(w io.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) error {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(label + `="` + escapeLabel(values[i]) + `"`)
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// Metrics of the server
+var (
+	HTTPRequests = NewCounter("adrive_http_requests_total",
+		"HTTP requests by method, route and status code.", "method", "route", "status")
+	HTTPDuration = NewHistogram("adrive_http_request_duration_seconds",
+		"Time to serve HTTP requests by method and route.", DefaultBuckets, "method", "route")
+
+	UploadBytes    = NewCounter("adrive_upload_bytes_total", "Bytes received in uploads.")
+	DownloadBytes  = NewCounter("adrive_download_bytes_total", "Bytes sent as file downloads.")
+	ActiveUploads  = NewGauge("adrive_active_uploads", "Uploads in progress.")
+	ShareDownloads = NewCounter("adrive_share_downloads_total", "Downloads of shared files.")
+
+	DBQueryDuration = NewHistogram("adrive_db_query_duration_seconds",
+		"Time spent in database statements by operation.",
+		[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}, "operation")
+	DBErrors = NewCounter("adrive_db_errors_total", "Failed database statements by operation.", "operation")
+
+	JobRuns = NewCounter("adrive_job_runs_total",
+		"Background job runs by job and result, success or failure.", "job", "result")
+	JobDuration = NewHistogram("adrive_job_duration_seconds",
+		"Time background jobs take.", []float64{0.01, 0.1, 1, 10, 60, 300, 1800}, "job")
+	JobLastSuccess = NewGauge("adrive_job_last_success_timestamp_seconds",
+		"Unix time of the last successful run of each background job.", "job")
+
+	startTime = NewGauge("process_start_time_seconds", "Unix time the server started.")
+)
+
+func init() {
+	startTime.Set(float64(time.Now().Unix()))
+
+	NewGaugeFunc("go_goroutines", "Goroutines that currently exist.", "", func() (map[string]float64, error) {
+		return map[string]float64{"": float64(runtime.NumGoroutine())}, nil
+	})
+	NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", "", func() (map[string]float64, error) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return map[string]float64{"": float64(stats.HeapAlloc)}, nil
+	})
+}
@@ -7,13 +7,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"a-drive-backend/authz"
+	"a-drive-backend/logging"
 	"a-drive-backend/models"
 	"a-drive-backend/utils"
 )
 
+// DatabaseMiddleware hands handlers the database, bound to the request's
+// context
 func DatabaseMiddleware(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("db", db)
+		c.Set("db", db.WithContext(c.Request.Context()))
 		c.Next()
 	}
 }
@@ -43,20 +47,29 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if user.Disabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		setRequestContext(c, logging.With(c.Request.Context(), "user_id", user.ID))
 		c.Next()
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission rejects users whose role does not grant the permission
+func RequirePermission(permission authz.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := c.MustGet("db").(*gorm.DB)
 		user := c.MustGet("user").(models.User)
-		if !user.IsAdmin() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		if !authz.Has(db, &user, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 			c.Abort()
 			return
 		}
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"a-drive-backend/logging"
+)
+
+// RequestIDHeader carries the ID of a request. Clients and proxies may set
+// it; otherwise the server makes one. It is returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware gives every request an ID and a logger that includes
+// it in the request's context
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		setRequestContext(c, logging.With(c.Request.Context(), "request_id", id))
+		c.Next()
+	}
+}
+
+// LoggerMiddleware logs every request when it is done, with the logger of
+// its context. Query parameters with credentials are redacted; headers are
+// only logged at debug level and with credentials redacted.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		ctx := c.Request.Context()
+		log := logging.FromContext(ctx)
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if query := logging.RedactQuery(c.Request.URL.RawQuery); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		if log.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", logging.RedactHeaders(c.Request.Header)))
+		}
+		log.LogAttrs(ctx, level, "Request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic in a handler into a 500 response and logs
+// it with its stack
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				logging.FromContext(c.Request.Context()).Error("Handler panicked",
+					"error", fmt.Sprint(err), "stack", string(debug.Stack()))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+		}()
+		c.Next()
+	}
+}
+
+// setRequestContext replaces the request's context and binds the database
+// handle to it, so that statements are logged with the request's logger
+func setRequestContext(c *gin.Context, ctx context.Context) {
+	c.Request = c.Request.WithContext(ctx)
+	if db, ok := c.Get("db"); ok {
+		c.Set("db", db.(*gorm.DB).WithContext(ctx))
+	}
+}
+
+// validRequestID accepts IDs from clients that are short and printable, so
+// they cannot forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
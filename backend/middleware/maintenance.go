@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/maintenance"
+)
+
+// maintenanceExempt are routes that keep accepting requests in maintenance
+// mode: logging in, so that admins can turn it off again, unlocking password
+// shares, which only reads them, and the switch itself
+var maintenanceExempt = map[string]bool{
+	"/api/auth/login":        true,
+	"/api/admin/maintenance": true,
+	"/share/:token":          true,
+}
+
+// MaintenanceMiddleware rejects requests that may change data with 503 while
+// maintenance mode is on. Reads keep working.
+func MaintenanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		// Unknown routes still get their 404
+		if c.FullPath() == "" || maintenanceExempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		state := maintenance.Get()
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", "120")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": state.Message, "maintenance": true})
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/metrics"
+)
+
+// MetricsMiddleware records requests by route, the bytes of uploads and
+// downloads, and the uploads in progress. Multipart requests count as
+// uploads and attachments as downloads.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		upload := c.Request.Method != "GET" && strings.HasPrefix(c.ContentType(), "multipart/")
+		if upload {
+			metrics.ActiveUploads.Inc()
+			defer metrics.ActiveUploads.Dec()
+			c.Request.Body = &countingReader{ReadCloser: c.Request.Body}
+		}
+
+		c.Next()
+
+		// The route pattern keeps IDs and tokens out of the labels
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequests.Inc(c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+		metrics.HTTPDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+
+		if strings.HasPrefix(c.Writer.Header().Get("Content-Disposition"), "attachment") && c.Writer.Size() > 0 {
+			metrics.DownloadBytes.Add(float64(c.Writer.Size()))
+		}
+	}
+}
+
+// countingReader adds the bytes read from a request body to the upload
+// counter as they arrive
+type countingReader struct {
+	io.ReadCloser
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		metrics.UploadBytes.Add(float64(n))
+	}
+	return n, err
+}
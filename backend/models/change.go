@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Change is an entry in the append-only change journal used by sync clients.
+// The primary key doubles as the cursor and only ever increases.
+type Change struct {
+	ID        uint      `json:"cursor" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	DriveID   *uint     `json:"drive_id" gorm:"index"` // team drive changes are visible to all members
+	ItemType  string    `json:"item_type" gorm:"not null;check:item_type IN ('file','folder')"`
+	ItemID    uint      `json:"item_id" gorm:"not null"`
+	Action    string    `json:"action" gorm:"not null"` // "create", "update", "rename", "move", "delete"
+	Name      string    `json:"name"`
+	ParentID  *uint     `json:"parent_id"` // folder_id for files, parent_id for folders
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Change) TableName() string {
+	return "changes"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Chunk is a piece of version content in the chunk store, shared by every
+// version that contains it
+type Chunk struct {
+	Hash      string    `json:"hash" gorm:"size:64;primaryKey"` // SHA-256 of the content
+	Size      int64     `json:"size" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"` // last stored or reused
+}
+
+// VersionChunk places a chunk at a position of a chunked version
+type VersionChunk struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	VersionID uint   `json:"version_id" gorm:"not null;uniqueIndex:idx_version_chunk_seq"`
+	Seq       int    `json:"seq" gorm:"not null;uniqueIndex:idx_version_chunk_seq"`
+	ChunkHash string `json:"chunk_hash" gorm:"size:64;not null;index"`
+}
@@ -5,17 +5,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// Favorite marks a file or folder for a user. A unique index on
+// (user_id, item_type, item_id), created by a migration, keeps an item from
+// being favorited twice.
 type Favorite struct {
 	ID       uint      `json:"id" gorm:"primaryKey"`
 	UserID   uint      `json:"user_id" gorm:"not null"`
-	ItemType string    `json:"item_type" gorm:"not null;check:item_type IN ('file','folder')"`
+	ItemType string    `json:"item_type" gorm:"size:20;not null;check:item_type IN ('file','folder')"`
 	ItemID   uint      `json:"item_id" gorm:"not null"`
 	CreatedAt time.Time `json:"created_at"`
 	
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
-// Add unique constraint to prevent duplicate favorites
 func (Favorite) TableName() string {
 	return "favorites"
 }
@@ -10,10 +10,12 @@ type File struct {
 	Name         string         `json:"name" gorm:"not null"`
 	OriginalName string         `json:"original_name" gorm:"not null"`
 	FolderID     *uint          `json:"folder_id"`
+	DriveID      *uint          `json:"drive_id" gorm:"index"` // set for files in a team drive
 	UserID       uint           `json:"user_id" gorm:"not null"`
 	FilePath     string         `json:"file_path" gorm:"not null"`
 	Size         int64          `json:"size" gorm:"not null"`
 	MimeType     string         `json:"mime_type"`
+	Checksum     string         `json:"checksum"` // MD5 of the current content
 	CurrentVersion int          `json:"current_version" gorm:"default:1"`
 	VersioningEnabled bool      `json:"versioning_enabled" gorm:"default:false"`
 	CreatedAt    time.Time      `json:"created_at"`
@@ -5,31 +5,59 @@ import (
 	"gorm.io/gorm"
 )
 
+// Share statuses. Shares end when they expire or reach their download limit;
+// changing those settings makes them active again.
+const (
+	ShareActive    = "active"
+	ShareExpired   = "expired"
+	ShareExhausted = "exhausted"
+)
+
 type FileShare struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	FileID      uint           `json:"file_id" gorm:"not null"`
-	File        File           `json:"file,omitempty" gorm:"foreignKey:FileID"`
+	FileID      *uint          `json:"file_id"`                    // Set for file shares
+	File        *File          `json:"file,omitempty" gorm:"foreignKey:FileID"`
+	FolderID    *uint          `json:"folder_id" gorm:"index"`     // Set for folder shares
+	Folder      *Folder        `json:"folder,omitempty" gorm:"foreignKey:FolderID"`
 	SharedBy    uint           `json:"shared_by" gorm:"not null"`
 	SharedByUser User          `json:"shared_by_user,omitempty" gorm:"foreignKey:SharedBy"`
-	ShareToken  string         `json:"share_token" gorm:"uniqueIndex;not null"`
+	ShareToken  string         `json:"share_token" gorm:"size:64;uniqueIndex;not null"`
+	Slug        *string        `json:"slug" gorm:"size:64;uniqueIndex"`   // Optional readable name used in place of the token
 	ShareType   string         `json:"share_type" gorm:"not null"` // "public", "password", "private"
 	Password    string         `json:"-" gorm:"column:password"`   // Only for password-protected shares
 	ExpiresAt   *time.Time     `json:"expires_at"`                 // Optional expiration
 	DownloadCount int          `json:"download_count" gorm:"default:0"`
 	MaxDownloads  *int         `json:"max_downloads"`              // Optional download limit
 	AllowPreview bool          `json:"allow_preview" gorm:"default:true"`
+	NotifyOnFirstDownload bool `json:"notify_on_first_download"` // notify the owner when the share is first downloaded
+	FirstDownloadedAt *time.Time `json:"first_downloaded_at"`
+	Disabled    bool           `json:"disabled"`                   // Turned off by the owner without deleting it
+	Status      string         `json:"status" gorm:"size:20;default:active;index"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// ItemName is the name of the shared file or folder, if it was loaded
+func (s *FileShare) ItemName() string {
+	if s.Folder != nil {
+		return s.Folder.Name
+	}
+	if s.File != nil {
+		return s.File.Name
+	}
+	return ""
+}
+
 type ShareAccess struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	ShareID     uint           `json:"share_id" gorm:"not null"`
+	ShareID     uint           `json:"share_id" gorm:"not null;index"`
 	Share       FileShare      `json:"share,omitempty" gorm:"foreignKey:ShareID"`
 	AccessedBy  string         `json:"accessed_by"` // IP address or identifier
-	AccessedAt  time.Time      `json:"accessed_at"`
+	AccessedAt  time.Time      `json:"accessed_at" gorm:"index"`
 	UserAgent   string         `json:"user_agent"`
-	Action      string         `json:"action"` // "view", "download"
+	Action      string         `json:"action"` // "view", "download", "preview"
+	Referrer    string         `json:"referrer"`
+	Country     string         `json:"country"` // ISO code from the GeoIP database, if configured
 	CreatedAt   time.Time      `json:"created_at"`
 }
\ No newline at end of file
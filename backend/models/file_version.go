@@ -9,10 +9,13 @@ type FileVersion struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	FileID      uint           `json:"file_id" gorm:"not null"`
 	Version     int            `json:"version" gorm:"not null"`
-	FilePath    string         `json:"file_path" gorm:"not null"`
+	FilePath    string         `json:"file_path" gorm:"not null"` // empty for chunked versions
 	Size        int64          `json:"size" gorm:"not null"`
 	Checksum    string         `json:"checksum"`
+	Chunked     bool           `json:"chunked" gorm:"default:false"` // content is kept in the chunk store
 	Comment     string         `json:"comment"`
+	Label       string         `json:"label"`                      // short name such as "release-1.2"
+	Pinned      bool           `json:"pinned" gorm:"default:false"` // pinned versions are never pruned
 	CreatedBy   uint           `json:"created_by" gorm:"not null"`
 	CreatedAt   time.Time      `json:"created_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -9,6 +9,7 @@ type Folder struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	Name      string         `json:"name" gorm:"not null"`
 	ParentID  *uint          `json:"parent_id"`
+	DriveID   *uint          `json:"drive_id" gorm:"index"` // set for folders in a team drive
 	UserID    uint           `json:"user_id" gorm:"not null"`
 	IconType  string         `json:"icon_type" gorm:"default:folder"`
 	IconColor string         `json:"icon_color" gorm:"default:text-blue-500"`
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Group is a named set of users that can own team drives
+type Group struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:255;unique;not null"`
+	Description string    `json:"description"`
+	CreatedBy   uint      `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Members []GroupMember `json:"members,omitempty" gorm:"foreignKey:GroupID"`
+}
+
+// GroupMember links a user to a group. Group admins manage the membership
+// and are managers of every drive the group owns.
+type GroupMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	GroupID   uint      `json:"group_id" gorm:"not null;uniqueIndex:idx_group_member"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_group_member"`
+	IsAdmin   bool      `json:"is_admin" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
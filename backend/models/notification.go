@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	NotificationShareFirstDownload = "share.first_download"
+	NotificationShareExpired       = "share.expired"
+	NotificationShareExhausted     = "share.exhausted"
+)
+
+// Notification tells a user about something that happened to their items
+// while they were away
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Type      string     `json:"type" gorm:"not null"`
+	Message   string     `json:"message"`
+	FileID    *uint      `json:"file_id"`
+	ShareID   *uint      `json:"share_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
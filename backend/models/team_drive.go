@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team drive roles, from least to most privileged
+const (
+	DriveRoleReader      = "reader"      // browse and download
+	DriveRoleContributor = "contributor" // add, change and delete content
+	DriveRoleManager     = "manager"     // share files, edit the drive and member roles
+)
+
+// TeamDrive is a top-level space owned by a group instead of a user. Its
+// files live under ROOT_DIRECTORY/drives/<id>.
+type TeamDrive struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	GroupID     uint           `json:"group_id" gorm:"not null;index"`
+	QuotaBytes  int64          `json:"quota_bytes" gorm:"default:0"` // 0 means unlimited
+	DefaultRole string         `json:"default_role" gorm:"default:contributor;check:default_role IN ('reader','contributor','manager')"`
+	CreatedBy   uint           `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Role        string         `json:"role,omitempty" gorm:"-"` // the requesting user's role
+	UsedBytes   int64          `json:"used_bytes" gorm:"-"`
+
+	Group Group `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+}
+
+// TeamDriveMember overrides the drive's default role for one group member
+type TeamDriveMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DriveID   uint      `json:"drive_id" gorm:"not null;uniqueIndex:idx_drive_member"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_drive_member"`
+	Role      string    `json:"role" gorm:"not null;check:role IN ('reader','contributor','manager')"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
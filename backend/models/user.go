@@ -7,10 +7,11 @@ import (
 
 type User struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
-	Username     string         `json:"username" gorm:"unique;not null"`
-	Email        string         `json:"email" gorm:"unique;not null"`
+	Username     string         `json:"username" gorm:"size:255;unique;not null"`
+	Email        string         `json:"email" gorm:"size:255;unique;not null"`
 	PasswordHash string         `json:"-" gorm:"not null"`
 	Role         string         `json:"role" gorm:"default:user"`
+	Disabled     bool           `json:"disabled" gorm:"default:false"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
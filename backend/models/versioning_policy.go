@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// VersioningPolicy turns on versioning for the files below a folder, or for
+// all personal files of a user, and decides which old versions are kept.
+// Exactly one of FolderID and UserID is set. Zero rules keep everything.
+type VersioningPolicy struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	FolderID        *uint     `json:"folder_id" gorm:"uniqueIndex"`
+	UserID          *uint     `json:"user_id" gorm:"uniqueIndex"`
+	Enabled         bool      `json:"enabled"`           // version files automatically when they are overwritten
+	KeepLast        int       `json:"keep_last"`         // the newest N old versions
+	KeepDailyDays   int       `json:"keep_daily_days"`   // the last version of each of the last N days
+	KeepWeeklyWeeks int       `json:"keep_weekly_weeks"` // the last version of each of the last N weeks
+	MaxTotalBytes   int64     `json:"max_total_bytes"`   // cap on the size of all old versions of a file
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// HasRetention reports whether the policy removes any old versions
+func (p *VersioningPolicy) HasRetention() bool {
+	return p.KeepLast > 0 || p.KeepDailyDays > 0 || p.KeepWeeklyWeeks > 0 || p.MaxTotalBytes > 0
+}
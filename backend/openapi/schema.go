@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields describes an ad-hoc JSON object such as a gin.H response. Each
+// value is an example of the property's type, e.g.
+// Fields{"file": models.File{}, "count": 0}.
+type Fields map[string]interface{}
+
+var (
+	fieldsType        = reflect.TypeOf(Fields{})
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaRegistry turns Go types into schemas. Named structs are emitted once
+// under components/schemas and referenced, which also terminates recursive
+// relations such as File -> User -> Files.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaFor returns the schema for the type of v, or nil when v is nil
+func (r *schemaRegistry) schemaFor(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	if fields, ok := v.(Fields); ok {
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for name, example := range fields {
+			prop := r.schemaFor(example)
+			if prop == nil {
+				prop = &Schema{Nullable: true}
+			}
+			s.Properties[name] = prop
+		}
+		return s
+	}
+	return r.schemaForType(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaForType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := r.schemaForType(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		nullable := *s
+		nullable.Nullable = true
+		return &nullable
+	}
+
+	switch {
+	case t == fieldsType:
+		return &Schema{Type: "object"}
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: intFormat(t)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Format: intFormat(t), Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaForType(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+			return &Schema{}
+		}
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.namedStruct(t)
+	}
+	return &Schema{}
+}
+
+func (r *schemaRegistry) namedStruct(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; r.schemas[name] != nil; i++ {
+			name = t.Name() + strconv.Itoa(i)
+		}
+		r.names[t] = name
+		// Reserve the name before descending so recursive references resolve
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omit := jsonName(field)
+		if omit {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened like encoding/json does
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaForType(field.Type)
+		rules := bindingRules(field)
+		if len(rules.enum) > 0 || rules.minLength != nil || rules.format != "" {
+			constrained := *prop
+			if len(rules.enum) > 0 {
+				constrained.Enum = rules.enum
+			}
+			if rules.minLength != nil && constrained.Type == "string" {
+				constrained.MinLength = rules.minLength
+			}
+			if rules.format != "" {
+				constrained.Format = rules.format
+			}
+			prop = &constrained
+		}
+		s.Properties[name] = prop
+		if rules.required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// jsonName returns the JSON property name of a field and whether it is skipped
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name := strings.Split(tag, ",")[0]
+	return name, false
+}
+
+type fieldRules struct {
+	required  bool
+	enum      []string
+	minLength *int
+	format    string
+}
+
+// bindingRules translates the validator tags used with ShouldBindJSON
+func bindingRules(field reflect.StructField) fieldRules {
+	var rules fieldRules
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			rules.required = true
+		case "oneof":
+			rules.enum = strings.Fields(value)
+		case "min":
+			if n, err := strconv.Atoi(value); err == nil {
+				rules.minLength = &n
+			}
+		case "email":
+			rules.format = "email"
+		}
+	}
+	return rules
+}
+
+func intFormat(t reflect.Type) string {
+	if t.Bits() == 64 {
+		return "int64"
+	}
+	return "int32"
+}
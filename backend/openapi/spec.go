@@ -0,0 +1,306 @@
+// Package openapi builds an OpenAPI 3 document from the routes registered on
+// a Gin engine and the request/response structs the handlers bind, and can
+// validate incoming requests against it.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operation documents one registered route. Path uses Gin syntax
+// (/api/files/:id). Path parameters are derived from the pattern and default
+// to integers when named "id" or "*_id"; Params overrides or extends them.
+type Operation struct {
+	Method          string
+	Path            string
+	Summary         string
+	Tag             string
+	Public          bool // reachable without a bearer token
+	Params          []Param
+	Request         interface{} // JSON request body, e.g. handlers.LoginRequest{}
+	RequestOptional bool        // the JSON body may be omitted
+	Form            []FormField // multipart/form-data request body
+	Response        interface{} // JSON body of the success response
+	Status          int         // success status, defaults to 200
+	ContentType     string      // success content type when not JSON, e.g. "application/octet-stream"
+	HTML            bool        // browsers that prefer text/html get a page instead
+}
+
+// Param is a path or query parameter
+type Param struct {
+	Name        string
+	In          string // "path" or "query"; defaults to "query"
+	Type        string // "string", "integer" or "boolean"; defaults to "string"
+	Enum        []string
+	Required    bool
+	Description string
+}
+
+// FormField is a field of a multipart/form-data body
+type FormField struct {
+	Name        string
+	File        bool
+	Required    bool
+	Description string
+}
+
+// Spec lazily builds the document once every route has been registered
+type Spec struct {
+	info       Info
+	routes     func() gin.RoutesInfo
+	operations []Operation
+
+	once     sync.Once
+	doc      *Document
+	problems []string
+	index    map[string]*OperationObject
+}
+
+// New creates a spec for the routes returned by routes (usually engine.Routes)
+func New(info Info, routes func() gin.RoutesInfo, operations []Operation) *Spec {
+	return &Spec{info: info, routes: routes, operations: operations}
+}
+
+// Document returns the generated OpenAPI document
+func (s *Spec) Document() *Document {
+	s.once.Do(s.build)
+	return s.doc
+}
+
+// Problems lists the differences found between the documented operations and
+// the registered routes
+func (s *Spec) Problems() []string {
+	s.once.Do(s.build)
+	return s.problems
+}
+
+// Handler serves the document as JSON
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.Document())
+	}
+}
+
+// operation looks up the generated operation for a Gin route pattern
+func (s *Spec) operation(method, ginPath string) *OperationObject {
+	s.once.Do(s.build)
+	return s.index[method+" "+ginPath]
+}
+
+func (s *Spec) build() {
+	registry := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    s.info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+	s.index = make(map[string]*OperationObject)
+
+	documented := make(map[string]Operation)
+	for _, op := range s.operations {
+		key := strings.ToUpper(op.Method) + " " + op.Path
+		if _, dup := documented[key]; dup {
+			s.problems = append(s.problems, fmt.Sprintf("%s is documented twice", key))
+		}
+		documented[key] = op
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range s.routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+
+		op, ok := documented[key]
+		if !ok {
+			s.problems = append(s.problems, fmt.Sprintf("%s is registered but not documented", key))
+			op = Operation{Method: route.Method, Path: route.Path, Summary: handlerName(route.Handler)}
+		}
+
+		obj := buildOperation(registry, op)
+		openAPIPath := toOpenAPIPath(route.Path)
+		item := doc.Paths[openAPIPath]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[openAPIPath] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = obj
+		s.index[key] = obj
+	}
+
+	for key := range documented {
+		if !registered[key] {
+			s.problems = append(s.problems, fmt.Sprintf("%s is documented but not registered", key))
+		}
+	}
+	sort.Strings(s.problems)
+
+	doc.Components.Schemas = registry.schemas
+	s.doc = doc
+}
+
+func buildOperation(registry *schemaRegistry, op Operation) *OperationObject {
+	obj := &OperationObject{
+		OperationID: operationID(op),
+		Summary:     op.Summary,
+		Responses:   make(map[string]Response),
+	}
+	if op.Tag != "" {
+		obj.Tags = []string{op.Tag}
+	}
+	if op.Public {
+		obj.Security = &[]map[string][]string{}
+	}
+
+	obj.Parameters = buildParameters(op)
+
+	switch {
+	case op.Request != nil:
+		obj.RequestBody = &RequestBodyObject{
+			Required: !op.RequestOptional,
+			Content:  map[string]MediaType{"application/json": {Schema: registry.schemaFor(op.Request)}},
+		}
+	case len(op.Form) > 0:
+		form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, field := range op.Form {
+			prop := &Schema{Type: "string", Description: field.Description}
+			if field.File {
+				prop.Format = "binary"
+			}
+			form.Properties[field.Name] = prop
+			if field.Required {
+				form.Required = append(form.Required, field.Name)
+			}
+		}
+		obj.RequestBody = &RequestBodyObject{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case op.ContentType != "":
+		success.Content = map[string]MediaType{op.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case op.Response != nil:
+		success.Content = map[string]MediaType{"application/json": {Schema: registry.schemaFor(op.Response)}}
+	}
+	if op.HTML {
+		if success.Content == nil {
+			success.Content = map[string]MediaType{}
+		}
+		success.Content["text/html"] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	obj.Responses[strconv.Itoa(status)] = success
+
+	errorSchema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	}
+	obj.Responses["default"] = Response{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+	}
+	return obj
+}
+
+func buildParameters(op Operation) []ParameterObject {
+	var params []ParameterObject
+	overrides := make(map[string]Param)
+	for _, p := range op.Params {
+		if p.In == "path" {
+			overrides[p.Name] = p
+		}
+	}
+
+	for _, segment := range strings.Split(op.Path, "/") {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		name := segment[1:]
+		p, ok := overrides[name]
+		if !ok {
+			p = Param{Name: name, In: "path"}
+			if name == "id" || strings.HasSuffix(name, "_id") {
+				p.Type = "integer"
+			}
+		}
+		p.Required = true
+		params = append(params, parameterObject(p))
+	}
+
+	for _, p := range op.Params {
+		if p.In != "path" {
+			p.In = "query"
+			params = append(params, parameterObject(p))
+		}
+	}
+	return params
+}
+
+func parameterObject(p Param) ParameterObject {
+	typ := p.Type
+	if typ == "" {
+		typ = "string"
+	}
+	schema := &Schema{Type: typ, Enum: p.Enum}
+	if typ == "integer" {
+		zero := 0.0
+		schema.Minimum = &zero
+	}
+	return ParameterObject{
+		Name:        p.Name,
+		In:          p.In,
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      schema,
+	}
+}
+
+// toOpenAPIPath converts /files/:id/*path into /files/{id}/{path}
+func toOpenAPIPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, segment := range strings.Split(op.Path, "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// handlerName turns "a-drive-backend/handlers.ListFiles" into "ListFiles"
+func handlerName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -0,0 +1,279 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedBody caps how much of a JSON body is buffered for validation
+const maxValidatedBody = 1 << 20
+
+// ValidationError describes one violation found in a request
+type ValidationError struct {
+	Location string `json:"location"` // e.g. "path.id", "query.limit", "body.items[0].name"
+	Message  string `json:"message"`
+}
+
+// ValidationMiddleware rejects requests that do not match the documented
+// parameters and request bodies with 400 and a list of violations. Routes
+// without a documented operation pass through untouched.
+func (s *Spec) ValidationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		errs := s.validateParameters(c, op)
+		errs = append(errs, s.validateBody(c, op)...)
+		if len(errs) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Request validation failed",
+				"details": errs,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Spec) validateParameters(c *gin.Context, op *OperationObject) []ValidationError {
+	var errs []ValidationError
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		if param.In == "path" {
+			value = c.Param(param.Name)
+			present = value != ""
+		} else {
+			value, present = c.GetQuery(param.Name)
+		}
+
+		location := param.In + "." + param.Name
+		if !present {
+			if param.Required {
+				errs = append(errs, ValidationError{location, "is required"})
+			}
+			continue
+		}
+		if msg := checkScalar(param.Schema, value); msg != "" {
+			errs = append(errs, ValidationError{location, msg})
+		}
+	}
+	return errs
+}
+
+func (s *Spec) validateBody(c *gin.Context, op *OperationObject) []ValidationError {
+	if op.RequestBody == nil {
+		return nil
+	}
+	if !op.RequestBody.Required && c.Request.ContentLength == 0 {
+		return nil
+	}
+
+	contentType := c.ContentType()
+	if media, ok := op.RequestBody.Content["multipart/form-data"]; ok && contentType == "multipart/form-data" {
+		var errs []ValidationError
+		for _, name := range media.Schema.Required {
+			if media.Schema.Properties[name].Format == "binary" {
+				if _, err := c.FormFile(name); err != nil {
+					errs = append(errs, ValidationError{"body." + name, "is required"})
+				}
+			} else if _, ok := c.GetPostForm(name); !ok {
+				errs = append(errs, ValidationError{"body." + name, "is required"})
+			}
+		}
+		return errs
+	}
+
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	if contentType != "application/json" {
+		return []ValidationError{{"body", "content type must be application/json"}}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxValidatedBody+1))
+	if err != nil {
+		return []ValidationError{{"body", "could not be read"}}
+	}
+	if len(data) > maxValidatedBody {
+		return []ValidationError{{"body", "is too large to validate"}}
+	}
+	// Hand the handler an untouched copy of the body
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []ValidationError{{"body", "is not valid JSON"}}
+	}
+
+	var errs []ValidationError
+	s.validateValue(media.Schema, value, "body", &errs)
+	return errs
+}
+
+// validateValue checks a decoded JSON value against a schema
+func (s *Spec) validateValue(schema *Schema, value interface{}, location string, errs *[]ValidationError) {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return
+	}
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			*errs = append(*errs, ValidationError{location, "must not be null"})
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*errs = append(*errs, ValidationError{location, "must be an object"})
+			return
+		}
+		// Like gin's required rule, a pointer field only has to be present,
+		// while other fields must not be zero
+		for _, name := range schema.Required {
+			v, ok := obj[name]
+			if prop := schema.Properties[name]; !ok || v == nil || (prop == nil || !prop.Nullable) && isZero(v) {
+				*errs = append(*errs, ValidationError{location + "." + name, "is required"})
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := schema.Properties[name]; ok {
+				s.validateValue(prop, obj[name], location+"."+name, errs)
+			} else if schema.AdditionalProperties != nil {
+				s.validateValue(schema.AdditionalProperties, obj[name], location+"."+name, errs)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			*errs = append(*errs, ValidationError{location, "must be an array"})
+			return
+		}
+		for i, item := range items {
+			s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", location, i), errs)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*errs = append(*errs, ValidationError{location, "must be a string"})
+			return
+		}
+		if msg := checkString(schema, str); msg != "" {
+			*errs = append(*errs, ValidationError{location, msg})
+		}
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			*errs = append(*errs, ValidationError{location, checkScalar(schema, "")})
+			return
+		}
+		if msg := checkScalar(schema, num.String()); msg != "" {
+			*errs = append(*errs, ValidationError{location, msg})
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, ValidationError{location, "must be a boolean"})
+		}
+	}
+}
+
+// checkScalar validates a path, query or JSON number value given as text
+func checkScalar(schema *Schema, value string) string {
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "must be an integer"
+		}
+		if schema.Minimum != nil && float64(n) < *schema.Minimum {
+			return fmt.Sprintf("must be at least %g", *schema.Minimum)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "must be a number"
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be a boolean"
+		}
+	case "string":
+		return checkString(schema, value)
+	}
+	return ""
+}
+
+func checkString(schema *Schema, value string) string {
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if value == allowed {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(schema.Enum, ", ")
+	}
+	if schema.MinLength != nil && len(value) < *schema.MinLength {
+		return fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+	}
+	if schema.Format == "email" {
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "must be an email address"
+		}
+	}
+	if schema.Format == "date-time" && value != "" {
+		var t struct{ T json.RawMessage }
+		if json.Unmarshal([]byte(`{"T":"`+value+`"}`), &t) != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// isZero mirrors the "required" validator, which rejects zero values
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case bool:
+		return !v
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+// Package pages renders the HTML pages the server shows to people without the
+// frontend, such as the landing pages of share links. The templates are
+// embedded in the binary.
+package pages
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sync"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// CSP is the Content-Security-Policy of rendered pages. They run no scripts
+// and embed previews only from this server.
+const CSP = "default-src 'none'; img-src 'self' data:; media-src 'self'; frame-src 'self'; style-src 'unsafe-inline'; form-action 'self'; base-uri 'none'; frame-ancestors 'none'"
+
+var (
+	parseOnce sync.Once
+	templates map[string]*template.Template
+	parseErr  error
+)
+
+var funcs = template.FuncMap{
+	"size": FormatSize,
+	"date": func(t time.Time) string { return t.UTC().Format("2 Jan 2006 15:04 MST") },
+}
+
+// parse builds every page from the layout and its own template, which
+// defines the title and content blocks
+func parse() {
+	names, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		parseErr = err
+		return
+	}
+	templates = map[string]*template.Template{}
+	for _, entry := range names {
+		name := entry.Name()
+		if name == "layout.html" {
+			continue
+		}
+		t, err := template.New(name).Funcs(funcs).ParseFS(templateFiles, "templates/layout.html", "templates/"+name)
+		if err != nil {
+			parseErr = err
+			return
+		}
+		templates[name[:len(name)-len(".html")]] = t
+	}
+}
+
+// Render writes the page with the given name, such as "share" for
+// templates/share.html
+func Render(w io.Writer, name string, data interface{}) error {
+	parseOnce.Do(parse)
+	if parseErr != nil {
+		return parseErr
+	}
+	t, ok := templates[name]
+	if !ok {
+		return fmt.Errorf("pages: no template %q", name)
+	}
+	return t.ExecuteTemplate(w, "layout", data)
+}
+
+// FormatSize formats a size in bytes with a binary unit, e.g. 1.5 MB
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
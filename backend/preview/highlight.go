@@ -0,0 +1,66 @@
+package preview
+
+import (
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// codeStyle is the chroma style of highlighted code
+const codeStyle = "github"
+
+// extensionLanguages are the source files shown as code, with the chroma
+// lexer for each
+var extensionLanguages = map[string]string{
+	".go": "go", ".py": "python", ".pyw": "python",
+	".js": "javascript", ".mjs": "javascript", ".cjs": "javascript", ".jsx": "react",
+	".ts": "typescript", ".tsx": "tsx",
+	".java": "java", ".kt": "kotlin", ".kts": "kotlin",
+	".c": "c", ".h": "c", ".cc": "c++", ".cpp": "c++", ".cxx": "c++", ".hpp": "c++", ".hh": "c++",
+	".cs": "c#", ".rs": "rust", ".swift": "swift", ".rb": "ruby", ".php": "php",
+	".sh": "bash", ".bash": "bash", ".zsh": "bash",
+	".sql": "sql", ".css": "css", ".scss": "scss",
+	".json": "json", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".xml": "xml",
+}
+
+// codeFormatter writes spans with chroma's short class names, styled by
+// codeCSS, so that pages need no inline styles per token
+var codeFormatter = chromahtml.New(chromahtml.WithClasses(true), chromahtml.PreventSurroundingPre(true))
+
+// codeCSS styles the classes of codeFormatter and of highlighted Markdown
+// code blocks
+var codeCSS = func() string {
+	var b strings.Builder
+	if err := codeFormatter.WriteCSS(&b, styles.Get(codeStyle)); err != nil {
+		return ""
+	}
+	return b.String()
+}()
+
+// Language returns the language highlighted for a file name, or ""
+func Language(name string) string {
+	return extensionLanguages[strings.ToLower(filepath.Ext(name))]
+}
+
+// Highlight returns source code as HTML spans classed like chroma's styles.
+// Unknown languages are only escaped.
+func Highlight(source, language string) string {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return html.EscapeString(source)
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, source)
+	if err != nil {
+		return html.EscapeString(source)
+	}
+	var b strings.Builder
+	if err := codeFormatter.Format(&b, styles.Get(codeStyle), tokens); err != nil {
+		return html.EscapeString(source)
+	}
+	return b.String()
+}
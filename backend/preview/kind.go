@@ -0,0 +1,109 @@
+// Package preview decides how files are shown inline in the browser and
+// renders Markdown and source code as HTML pages.
+package preview
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of previewable files
+const (
+	Image    = "image"
+	PDF      = "pdf"
+	Audio    = "audio"
+	Video    = "video"
+	Text     = "text"
+	Markdown = "markdown"
+	Code     = "code"
+	HTML     = "html"
+	SVG      = "svg"
+)
+
+// imageTypes are the raster formats browsers display
+var imageTypes = map[string]bool{
+	"image/png": true, "image/jpeg": true, "image/gif": true, "image/webp": true,
+	"image/avif": true, "image/bmp": true, "image/x-icon": true, "image/vnd.microsoft.icon": true,
+}
+
+// textExtensions are plain text files without a language
+var textExtensions = map[string]bool{
+	".txt": true, ".log": true, ".csv": true, ".tsv": true, ".ini": true, ".conf": true,
+	".cfg": true, ".env": true, ".properties": true,
+}
+
+// Kind returns the kind of a file from its name and stored MIME type, or ""
+// when browsers cannot show it
+func Kind(name, mimeType string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case ext == ".md" || ext == ".markdown":
+		return Markdown
+	case ext == ".svg":
+		return SVG
+	case ext == ".html" || ext == ".htm":
+		return HTML
+	case Language(name) != "":
+		return Code
+	case textExtensions[ext]:
+		return Text
+	}
+
+	mediaType := ContentType(name, mimeType)
+	switch {
+	case imageTypes[mediaType]:
+		return Image
+	case mediaType == "image/svg+xml":
+		return SVG
+	case mediaType == "application/pdf":
+		return PDF
+	case strings.HasPrefix(mediaType, "audio/"):
+		return Audio
+	case strings.HasPrefix(mediaType, "video/"):
+		return Video
+	case mediaType == "text/html":
+		return HTML
+	case mediaType == "text/markdown":
+		return Markdown
+	case strings.HasPrefix(mediaType, "text/"):
+		return Text
+	}
+	return ""
+}
+
+// ContentType returns the media type of a file without parameters. The
+// extension wins over the stored type, which comes from the uploading client.
+func ContentType(name, mimeType string) string {
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); byExt != "" {
+		mimeType = byExt
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// IsText reports whether a kind is shown as text when the raw format is
+// requested
+func IsText(kind string) bool {
+	return kind == Text || kind == Markdown || kind == Code || kind == HTML || kind == SVG
+}
+
+// CSP is the Content-Security-Policy for serving a kind as it is. Documents
+// that can contain scripts are sandboxed without allow-scripts.
+func CSP(kind string) string {
+	switch kind {
+	case HTML, SVG:
+		return "sandbox; default-src 'none'; img-src data:; style-src 'unsafe-inline'"
+	case PDF:
+		// Browser PDF viewers do not work in a sandbox
+		return "default-src 'none'; object-src 'self'; style-src 'unsafe-inline'"
+	}
+	return "sandbox; default-src 'none'; img-src 'self' data:; media-src 'self'; style-src 'unsafe-inline'"
+}
+
+// PageCSP is the Content-Security-Policy of rendered Markdown and code pages.
+// They need no scripts and load no external content.
+const PageCSP = "sandbox; default-src 'none'; img-src data:; style-src 'unsafe-inline'"
@@ -0,0 +1,103 @@
+package preview
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// markdown renders CommonMark with GitHub's tables, task lists,
+// strikethrough and bare URLs. Raw HTML is left out, as goldmark does unless
+// told otherwise.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		highlighting.NewHighlighting(
+			highlighting.WithStyle(codeStyle),
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		),
+	),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(util.Prioritized(safeLinks{}, 100)),
+	),
+)
+
+// RenderMarkdown converts Markdown to HTML. Raw HTML in the source is
+// dropped and links and images may only use http, https and mailto URLs or
+// relative ones, so the result is safe to show.
+func RenderMarkdown(source string) string {
+	var out bytes.Buffer
+	if err := markdown.Convert([]byte(source), &out); err != nil {
+		return "<pre>" + html.EscapeString(source) + "</pre>\n"
+	}
+	return out.String()
+}
+
+// safeLinks replaces links and images whose URLs are not safe with their
+// text, and keeps the pages of other links from learning about the preview
+type safeLinks struct{}
+
+func (safeLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var unsafe []ast.Node
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var url []byte
+		switch n := n.(type) {
+		case *ast.Link:
+			url = n.Destination
+		case *ast.Image:
+			url = n.Destination
+		case *ast.AutoLink:
+			url = n.URL(source)
+		default:
+			return ast.WalkContinue, nil
+		}
+		if !safeURL(string(url)) {
+			unsafe = append(unsafe, n)
+			return ast.WalkSkipChildren, nil
+		}
+		if n.Kind() != ast.KindImage {
+			n.SetAttributeString("rel", []byte("nofollow noopener noreferrer"))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range unsafe {
+		parent := n.Parent()
+		if link, ok := n.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, n, ast.NewString(link.Label(source)))
+			continue
+		}
+		for child := n.FirstChild(); child != nil; child = n.FirstChild() {
+			parent.InsertBefore(parent, n, child)
+		}
+		parent.RemoveChild(parent, n)
+	}
+}
+
+// safeURL accepts http, https and mailto URLs and relative ones, rejecting
+// schemes such as javascript: and data:
+func safeURL(url string) bool {
+	colon := strings.IndexByte(url, ':')
+	if colon < 0 || strings.ContainsAny(url[:colon], "/?#") {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(url[:colon])) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package preview
+
+import (
+	"html"
+	"strings"
+)
+
+// pageStyle is inlined into rendered pages, which may not load stylesheets,
+// followed by codeCSS
+const pageStyle = `
+body { margin: 0 auto; max-width: 900px; padding: 24px; font: 16px/1.6 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; background: #fff; }
+h1, h2 { border-bottom: 1px solid #d1d9e0; padding-bottom: .3em; }
+a { color: #0969da; }
+img { max-width: 100%; }
+blockquote { margin: 0; padding: 0 1em; color: #59636e; border-left: .25em solid #d1d9e0; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d1d9e0; padding: 6px 13px; }
+code { font: 85% ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: #f6f8fa; padding: .2em .4em; border-radius: 6px; }
+pre { background: #f6f8fa; padding: 16px; overflow: auto; border-radius: 6px; line-height: 1.45; }
+pre code { padding: 0; background: none; font-size: 85%; }
+li > input[type=checkbox] { margin-right: .4em; }
+`
+
+// Page wraps rendered HTML in a standalone document
+func Page(title, body string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + pageStyle + codeCSS + "</style>\n</head>\n<body>\n")
+	b.WriteString(body)
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// RenderCode returns a page showing source code with syntax highlighting
+func RenderCode(name, source string) string {
+	return Page(name, "<pre class=\"source chroma\"><code>"+Highlight(source, Language(name))+"</code></pre>\n")
+}
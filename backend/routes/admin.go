@@ -2,15 +2,37 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"a-drive-backend/authz"
 	"a-drive-backend/handlers"
+	"a-drive-backend/middleware"
 )
 
 func SetupAdminRoutes(router *gin.RouterGroup) {
-	router.GET("/users", handlers.ListUsers)
-	router.POST("/users", handlers.CreateUser)
-	router.GET("/files", handlers.BrowseUserFiles)
+	users := middleware.RequirePermission(authz.AdminUsersManage)
+	router.GET("/users", users, handlers.ListUsers)
+	router.POST("/users", users, handlers.CreateUser)
+	router.PUT("/users/:id/role", users, handlers.UpdateUserRole)
+	router.POST("/users/:id/disable", users, handlers.DisableUser)
+	router.POST("/users/:id/enable", users, handlers.EnableUser)
+	router.POST("/users/:id/reset-password", users, handlers.ResetUserPassword)
+	router.DELETE("/users/:id", users, handlers.DeleteUser)
+	router.GET("/files", middleware.RequirePermission(authz.AdminFilesBrowse), handlers.BrowseUserFiles)
+	
+	// Roles and permissions
+	roles := middleware.RequirePermission(authz.AdminRolesManage)
+	router.GET("/permissions", roles, handlers.ListPermissions)
+	router.GET("/roles", roles, handlers.ListRoles)
+	router.POST("/roles", roles, handlers.CreateRole)
+	router.PUT("/roles/:id", roles, handlers.UpdateRole)
+	router.DELETE("/roles/:id", roles, handlers.DeleteRole)
 	
 	// Configuration endpoints
-	router.GET("/config", handlers.GetConfig)
-	router.GET("/cors", handlers.CORSInfo)
+	config := middleware.RequirePermission(authz.AdminConfigRead)
+	router.GET("/config", config, handlers.GetConfig)
+	router.GET("/cors", config, handlers.CORSInfo)
+
+	// Maintenance mode
+	maintenance := middleware.RequirePermission(authz.AdminMaintenance)
+	router.GET("/maintenance", maintenance, handlers.GetMaintenance)
+	router.PUT("/maintenance", maintenance, handlers.SetMaintenance)
 }
\ No newline at end of file
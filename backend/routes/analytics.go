@@ -2,10 +2,12 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"a-drive-backend/authz"
 	"a-drive-backend/handlers"
+	"a-drive-backend/middleware"
 )
 
 func SetupAnalyticsRoutes(router *gin.RouterGroup) {
-	router.GET("/analytics/system", handlers.GetSystemAnalytics)
+	router.GET("/analytics/system", middleware.RequirePermission(authz.AnalyticsSystem), handlers.GetSystemAnalytics)
 	router.GET("/analytics/user", handlers.GetUserAnalytics)
 }
\ No newline at end of file
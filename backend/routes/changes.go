@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"a-drive-backend/handlers"
+)
+
+func SetupChangesRoutes(router *gin.RouterGroup) {
+	router.GET("/changes", handlers.GetChanges)
+	router.GET("/changes/latest", handlers.GetLatestChangeCursor)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"a-drive-backend/authz"
+	"a-drive-backend/handlers"
+	"a-drive-backend/middleware"
+)
+
+func SetupDriveRoutes(router *gin.RouterGroup) {
+	router.GET("/drives", handlers.ListDrives)
+	router.POST("/drives", middleware.RequirePermission(authz.DrivesCreate), handlers.CreateDrive)
+	router.GET("/drives/:id", handlers.GetDrive)
+	router.PUT("/drives/:id", handlers.UpdateDrive)
+	router.DELETE("/drives/:id", handlers.DeleteDrive)
+	router.PUT("/drives/:id/members/:user_id", handlers.SetDriveMember)
+	router.DELETE("/drives/:id/members/:user_id", handlers.RemoveDriveMember)
+}
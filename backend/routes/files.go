@@ -9,9 +9,14 @@ func SetupFileRoutes(router *gin.RouterGroup) {
 	router.GET("/files", handlers.ListFiles)
 	router.GET("/photos", handlers.GetPhotos)
 	router.POST("/files/upload", handlers.UploadFile)
+	router.POST("/files/upload/batch", handlers.UploadBatch)
 	router.GET("/files/:id/download", handlers.DownloadFile)
+	router.GET("/files/:id/preview", handlers.PreviewFile)
 	router.DELETE("/files/:id", handlers.DeleteFile)
 	router.PUT("/files/:id", handlers.RenameFile)
+	router.PUT("/files/:id/content", handlers.ReplaceFileContent)
+	router.POST("/files/:id/move", handlers.MoveFile)
+	router.POST("/files/:id/copy", handlers.CopyFile)
 	
 	// Search functionality
 	router.GET("/search", handlers.SearchFiles)
@@ -20,11 +25,27 @@ func SetupFileRoutes(router *gin.RouterGroup) {
 	// Bulk operations
 	router.POST("/bulk", handlers.BulkOperation)
 	
+	// Archives
+	router.GET("/files/:id/archive", handlers.ListArchive)
+	router.GET("/files/:id/archive/member", handlers.DownloadArchiveMember)
+	router.POST("/files/:id/archive/extract", handlers.ExtractArchive)
+	
 	// File versioning
 	router.POST("/files/:id/versioning/enable", handlers.EnableVersioning)
 	router.POST("/files/:id/versioning/disable", handlers.DisableVersioning)
+	router.GET("/files/:id/versioning", handlers.GetEffectiveVersioningPolicy)
 	router.GET("/files/:id/versions", handlers.GetFileVersions)
 	router.POST("/files/:id/versions", handlers.CreateNewVersion)
+	router.GET("/files/:id/versions/diff", handlers.DiffVersions)
+	router.GET("/files/:id/versions/stats", handlers.GetFileVersionStats)
+	router.PUT("/files/:id/versions/:version_id", handlers.UpdateVersion)
+	router.DELETE("/files/:id/versions/:version_id", handlers.DeleteVersion)
 	router.POST("/files/:id/versions/:version_id/restore", handlers.RestoreVersion)
 	router.GET("/files/:id/versions/:version_id/download", handlers.DownloadVersion)
+	
+	// Default versioning policy for personal files
+	router.GET("/versioning/policy", handlers.GetUserVersioningPolicy)
+	router.PUT("/versioning/policy", handlers.SetUserVersioningPolicy)
+	router.DELETE("/versioning/policy", handlers.DeleteUserVersioningPolicy)
+	router.GET("/versioning/stats", handlers.GetVersioningStats)
 }
\ No newline at end of file
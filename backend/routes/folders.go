@@ -11,5 +11,10 @@ func SetupFolderRoutes(router *gin.RouterGroup) {
 	router.GET("/folders/:id/breadcrumbs", handlers.GetFolderBreadcrumbs)
 	router.PUT("/folders/:id", handlers.UpdateFolder)
 	router.DELETE("/folders/:id", handlers.DeleteFolder)
+	router.POST("/folders/:id/move", handlers.MoveFolder)
+	router.POST("/folders/:id/copy", handlers.CopyFolder)
 	router.POST("/folders/:id/zip", handlers.CreateZipArchive)
+	router.GET("/folders/:id/versioning", handlers.GetFolderVersioningPolicy)
+	router.PUT("/folders/:id/versioning", handlers.SetFolderVersioningPolicy)
+	router.DELETE("/folders/:id/versioning", handlers.DeleteFolderVersioningPolicy)
 }
\ No newline at end of file
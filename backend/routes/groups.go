@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"a-drive-backend/authz"
+	"a-drive-backend/handlers"
+	"a-drive-backend/middleware"
+)
+
+func SetupGroupRoutes(router *gin.RouterGroup) {
+	router.GET("/groups", handlers.ListGroups)
+	router.POST("/groups", middleware.RequirePermission(authz.GroupsCreate), handlers.CreateGroup)
+	router.GET("/groups/:id", handlers.GetGroup)
+	router.PUT("/groups/:id", handlers.UpdateGroup)
+	router.DELETE("/groups/:id", handlers.DeleteGroup)
+	router.POST("/groups/:id/members", handlers.AddGroupMember)
+	router.PUT("/groups/:id/members/:user_id", handlers.UpdateGroupMember)
+	router.DELETE("/groups/:id/members/:user_id", handlers.RemoveGroupMember)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"a-drive-backend/handlers"
+)
+
+// SetupHealthRoutes serves the liveness and readiness probes
+func SetupHealthRoutes(router *gin.Engine) {
+	router.GET("/livez", handlers.Livez)
+	router.GET("/readyz", handlers.Readyz)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"a-drive-backend/handlers"
+)
+
+// SetupMetricsRoutes serves Prometheus metrics. The route answers 404 when
+// metrics are disabled.
+func SetupMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", handlers.GetMetrics)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/handlers"
+)
+
+func SetupNotificationRoutes(router *gin.RouterGroup) {
+	router.GET("/notifications", handlers.GetNotifications)
+	router.POST("/notifications/read-all", handlers.MarkAllNotificationsRead)
+	router.POST("/notifications/:id/read", handlers.MarkNotificationRead)
+}
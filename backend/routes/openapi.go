@@ -0,0 +1,426 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"a-drive-backend/authz"
+	"a-drive-backend/handlers"
+	"a-drive-backend/health"
+	"a-drive-backend/maintenance"
+	"a-drive-backend/models"
+	"a-drive-backend/openapi"
+	"a-drive-backend/textdiff"
+	"a-drive-backend/versioning"
+)
+
+// NewOpenAPISpec documents every registered route. The spec is built lazily
+// from engine.Routes(), so it can be created before the routes are set up;
+// routes missing from apiOperations show up in Spec.Problems().
+func NewOpenAPISpec(engine *gin.Engine) *openapi.Spec {
+	return openapi.New(openapi.Info{
+		Title:       "A-Drive API",
+		Version:     "1.0.0",
+		Description: "Personal cloud storage API. Generated from the registered routes.",
+	}, engine.Routes, apiOperations)
+}
+
+// SetupOpenAPIRoutes serves the generated document
+func SetupOpenAPIRoutes(router *gin.RouterGroup, spec *openapi.Spec) {
+	router.GET("/openapi.json", spec.Handler())
+}
+
+var (
+	publicUser = openapi.Fields{"id": uint(0), "username": "", "email": "", "role": ""}
+	message    = openapi.Fields{"message": ""}
+	authResult = openapi.Fields{"token": "", "user": publicUser}
+	fileResult = openapi.Fields{"file": models.File{}}
+	folderID   = openapi.Param{Name: "folder_id", Description: `folder ID, or "root" for top-level items only`}
+	driveID    = openapi.Param{Name: "drive_id", Type: "integer", Description: "team drive, the personal space when omitted"}
+	binary     = "application/octet-stream"
+)
+
+var apiOperations = []openapi.Operation{
+	// Service
+	{Method: "GET", Path: "/health", Tag: "service", Public: true, Summary: "Health check",
+		Response: openapi.Fields{"status": "", "message": ""}},
+	{Method: "GET", Path: "/cors", Tag: "service", Public: true, Summary: "CORS configuration",
+		Response: openapi.Fields{"origins": []string{}, "methods": []string{}, "headers": []string{}, "message": ""}},
+	{Method: "GET", Path: "/api/openapi.json", Tag: "service", Public: true, Summary: "This OpenAPI document",
+		Response: openapi.Fields{}},
+	{Method: "GET", Path: "/livez", Tag: "service", Public: true, Summary: "Liveness probe",
+		Response: openapi.Fields{"status": ""}},
+	{Method: "GET", Path: "/readyz", Tag: "service", Public: true, Summary: "Readiness probe: database, storage and free space; 503 when not ready or shutting down",
+		Response: openapi.Fields{"status": "", "maintenance": false, "checks": map[string]health.Check{}}},
+	{Method: "GET", Path: "/metrics", Tag: "service", Public: true, Summary: "Prometheus metrics, with METRICS_TOKEN as bearer token when set",
+		ContentType: "text/plain"},
+
+	// Auth and profile
+	{Method: "POST", Path: "/api/auth/register", Tag: "auth", Public: true, Summary: "Register a new user",
+		Request: handlers.RegisterRequest{}, Response: authResult, Status: 201},
+	{Method: "POST", Path: "/api/auth/login", Tag: "auth", Public: true, Summary: "Log in and obtain a token",
+		Request: handlers.LoginRequest{}, Response: authResult},
+	{Method: "GET", Path: "/api/auth/me", Tag: "auth", Summary: "Current user and their permissions",
+		Response: openapi.Fields{"user": openapi.Fields{"id": uint(0), "username": "", "email": "", "role": "", "permissions": []string{}}}},
+	{Method: "GET", Path: "/api/profile", Tag: "profile", Summary: "Profile and storage statistics",
+		Response: openapi.Fields{"user": publicUser, "stats": handlers.UserStats{}}},
+	{Method: "PUT", Path: "/api/profile", Tag: "profile", Summary: "Update username or email",
+		Request: handlers.UpdateProfileRequest{}, Response: openapi.Fields{"message": "", "user": publicUser}},
+	{Method: "POST", Path: "/api/profile/change-password", Tag: "profile", Summary: "Change password",
+		Request: handlers.ChangePasswordRequest{}, Response: message},
+
+	// Files
+	{Method: "GET", Path: "/api/files", Tag: "files", Summary: "List folders and files",
+		Params:   []openapi.Param{folderID, driveID},
+		Response: openapi.Fields{"folders": []models.Folder{}, "files": []models.File{}}},
+	{Method: "GET", Path: "/api/photos", Tag: "files", Summary: "List image files",
+		Response: openapi.Fields{"files": []models.File{}, "count": 0}},
+	{Method: "POST", Path: "/api/files/upload", Tag: "files", Summary: "Upload a file",
+		Form: []openapi.FormField{
+			{Name: "file", File: true, Required: true},
+			{Name: "folder_id", Description: "destination folder, root when omitted"},
+			{Name: "drive_id", Description: "team drive for uploads to a drive root"},
+			{Name: "on_conflict", Description: "keep_both (default), reject, overwrite or skip"},
+		},
+		Response: openapi.Fields{"file": models.File{}, "result": ""}},
+	{Method: "POST", Path: "/api/files/upload/batch", Tag: "files", Summary: "Upload a folder tree or an archive",
+		Form: []openapi.FormField{
+			{Name: "files", File: true, Description: "repeated, one part per file"},
+			{Name: "paths", Description: "repeated, the relative path of each file in the same order"},
+			{Name: "archive", File: true, Description: "a .zip, .tar, .tar.gz or .tgz to unpack instead of files"},
+			{Name: "folder_id", Description: "target folder, root when omitted"},
+			{Name: "drive_id", Description: "team drive for uploads to a drive root"},
+			{Name: "on_conflict", Description: "keep_both (default), reject, overwrite or skip, for every file"},
+		},
+		Response: handlers.BatchUploadResult{}},
+	{Method: "GET", Path: "/api/files/:id/download", Tag: "files", Summary: "Download a file",
+		ContentType: binary},
+	{Method: "GET", Path: "/api/files/:id/preview", Tag: "files", Summary: "View a file inline",
+		Params: []openapi.Param{previewFormat}, ContentType: binary},
+	{Method: "DELETE", Path: "/api/files/:id", Tag: "files", Summary: "Delete a file",
+		Response: message},
+	{Method: "PUT", Path: "/api/files/:id", Tag: "files", Summary: "Rename a file",
+		Request: handlers.RenameFileRequest{}, Response: fileResult},
+	{Method: "PUT", Path: "/api/files/:id/content", Tag: "files", Summary: "Replace file content",
+		Form: []openapi.FormField{
+			{Name: "file", File: true, Required: true},
+			{Name: "comment", Description: "version comment when versioning is enabled"},
+		},
+		Response: fileResult},
+	{Method: "POST", Path: "/api/files/:id/move", Tag: "files", Summary: "Move a file within its space",
+		Request: handlers.TransferRequest{}, Response: movedFile},
+	{Method: "POST", Path: "/api/files/:id/copy", Tag: "files", Summary: "Copy a file to any writable folder",
+		Request: handlers.TransferRequest{}, Response: movedFile, Status: 201},
+	{Method: "GET", Path: "/api/search", Tag: "files", Summary: "Search files and folders",
+		Params: []openapi.Param{
+			{Name: "q", Required: true, Description: "search text, * and ? wildcards are supported"},
+			{Name: "type", Description: "file type category filter"},
+			{Name: "drive_id", Type: "integer", Description: "search one team drive, everything readable when omitted"},
+		},
+		Response: handlers.SearchResult{}},
+	{Method: "GET", Path: "/api/files/types", Tag: "files", Summary: "File type categories",
+		Response: openapi.Fields{"categories": []openapi.Fields{}}},
+	{Method: "POST", Path: "/api/bulk", Tag: "files", Summary: "Delete, move or download several items",
+		Request: handlers.BulkOperationRequest{}, Response: handlers.BulkOperationResult{}},
+
+	// Archives
+	{Method: "GET", Path: "/api/files/:id/archive", Tag: "archives", Summary: "List the members of a ZIP or tar file",
+		Response: openapi.Fields{"file": models.File{}, "members": []handlers.ArchiveMember{}, "truncated": false, "total_size": int64(0)}},
+	{Method: "GET", Path: "/api/files/:id/archive/member", Tag: "archives", Summary: "Download one member of an archive",
+		Params:      []openapi.Param{{Name: "path", Required: true, Description: "member path as listed"}},
+		ContentType: binary},
+	{Method: "POST", Path: "/api/files/:id/archive/extract", Tag: "archives", Summary: "Extract an archive into a folder",
+		Request: handlers.ExtractArchiveRequest{}, Response: handlers.BatchUploadResult{}},
+
+	// Versioning
+	{Method: "POST", Path: "/api/files/:id/versioning/enable", Tag: "versions", Summary: "Enable versioning",
+		Response: message},
+	{Method: "POST", Path: "/api/files/:id/versioning/disable", Tag: "versions", Summary: "Disable versioning",
+		Response: message},
+	{Method: "GET", Path: "/api/files/:id/versioning", Tag: "versions", Summary: "Versioning policy that applies to a file",
+		Response: openapi.Fields{"versioning_enabled": false, "policy": models.VersioningPolicy{}}},
+	{Method: "GET", Path: "/api/files/:id/versions", Tag: "versions", Summary: "List versions",
+		Response: openapi.Fields{"file": models.File{}, "versions": []models.FileVersion{}}},
+	{Method: "POST", Path: "/api/files/:id/versions", Tag: "versions", Summary: "Upload a new version",
+		Form: []openapi.FormField{
+			{Name: "file", File: true, Required: true},
+			{Name: "comment"},
+		},
+		Response: openapi.Fields{"message": "", "version": models.FileVersion{}}},
+	{Method: "GET", Path: "/api/files/:id/versions/diff", Tag: "versions", Summary: "Compare two versions of a text file",
+		Params: []openapi.Param{
+			{Name: "from", Required: true, Description: `version ID, or "current"`},
+			{Name: "to", Description: `version ID, or "current" (default)`},
+			{Name: "mode", Enum: []string{"unified", "side_by_side"}, Description: "side_by_side normalizes JSON and YAML"},
+			{Name: "context", Type: "integer", Description: "context lines of the unified diff, 3 by default"},
+		},
+		Response: openapi.Fields{
+			"file": models.File{}, "from": handlers.DiffSide{}, "to": handlers.DiffSide{}, "mode": "", "identical": false,
+			"unified": "", "lines": []textdiff.Line{}, "format": "", "rows": []textdiff.Row{},
+		}},
+	{Method: "GET", Path: "/api/files/:id/versions/stats", Tag: "versions", Summary: "Logical and stored bytes of a file's old versions",
+		Response: openapi.Fields{"file_id": uint(0), "stats": versioning.Stats{}}},
+	{Method: "PUT", Path: "/api/files/:id/versions/:version_id", Tag: "versions", Summary: "Edit the comment, label or pin of a version",
+		Request: handlers.UpdateVersionRequest{}, Response: openapi.Fields{"version": models.FileVersion{}}},
+	{Method: "DELETE", Path: "/api/files/:id/versions/:version_id", Tag: "versions", Summary: "Delete an old version",
+		Response: message},
+	{Method: "POST", Path: "/api/files/:id/versions/:version_id/restore", Tag: "versions", Summary: "Restore a version as a new version",
+		Response: openapi.Fields{"message": "", "restored_version": 0, "version": models.FileVersion{}, "file": models.File{}}},
+	{Method: "GET", Path: "/api/files/:id/versions/:version_id/download", Tag: "versions", Summary: "Download a version",
+		ContentType: binary},
+	{Method: "GET", Path: "/api/versioning/policy", Tag: "versions", Summary: "Default versioning policy for personal files",
+		Response: versioningPolicy},
+	{Method: "PUT", Path: "/api/versioning/policy", Tag: "versions", Summary: "Set the default versioning policy",
+		Request: handlers.VersioningPolicyRequest{}, Response: versioningPolicy},
+	{Method: "DELETE", Path: "/api/versioning/policy", Tag: "versions", Summary: "Remove the default versioning policy",
+		Response: message},
+	{Method: "GET", Path: "/api/versioning/stats", Tag: "versions", Summary: "Logical and stored bytes of all old versions in a space",
+		Params: []openapi.Param{driveID}, Response: openapi.Fields{"stats": versioning.Stats{}}},
+	{Method: "GET", Path: "/api/folders/:id/versioning", Tag: "versions", Summary: "Versioning policy of a folder",
+		Response: versioningPolicy},
+	{Method: "PUT", Path: "/api/folders/:id/versioning", Tag: "versions", Summary: "Set the versioning policy of a folder tree",
+		Request: handlers.VersioningPolicyRequest{}, Response: versioningPolicy},
+	{Method: "DELETE", Path: "/api/folders/:id/versioning", Tag: "versions", Summary: "Remove the versioning policy of a folder",
+		Response: message},
+
+	// Folders
+	{Method: "POST", Path: "/api/folders", Tag: "folders", Summary: "Create a folder",
+		Request: handlers.CreateFolderRequest{}, Response: openapi.Fields{"folder": models.Folder{}}, Status: 201},
+	{Method: "GET", Path: "/api/folders/:id", Tag: "folders", Summary: "Get a folder",
+		Response: openapi.Fields{"folder": models.Folder{}}},
+	{Method: "GET", Path: "/api/folders/:id/breadcrumbs", Tag: "folders", Summary: "Path from the root to a folder",
+		Params:   []openapi.Param{{Name: "id", In: "path", Description: `folder ID or "root"`}},
+		Response: openapi.Fields{"breadcrumbs": []openapi.Fields{}}},
+	{Method: "PUT", Path: "/api/folders/:id", Tag: "folders", Summary: "Rename a folder or change its icon",
+		Request: handlers.UpdateFolderRequest{}, Response: openapi.Fields{"folder": models.Folder{}}},
+	{Method: "DELETE", Path: "/api/folders/:id", Tag: "folders", Summary: "Delete a folder and its contents",
+		Response: message},
+	{Method: "POST", Path: "/api/folders/:id/move", Tag: "folders", Summary: "Move a folder tree within its space",
+		Request: handlers.TransferRequest{}, Response: movedFolder},
+	{Method: "POST", Path: "/api/folders/:id/copy", Tag: "folders", Summary: "Copy a folder tree to any writable folder",
+		Request: handlers.TransferRequest{}, Response: movedFolder, Status: 201},
+	{Method: "POST", Path: "/api/folders/:id/zip", Tag: "folders", Summary: "Download a folder as ZIP",
+		ContentType: "application/zip"},
+
+	// Favorites and recent items
+	{Method: "GET", Path: "/api/favorites", Tag: "favorites", Summary: "List favorites",
+		Response: openapi.Fields{"favorites": []openapi.Fields{}}},
+	{Method: "POST", Path: "/api/favorites", Tag: "favorites", Summary: "Add a favorite",
+		Request: handlers.FavoriteItemRequest{}, Response: openapi.Fields{"favorite": models.Favorite{}}, Status: 201},
+	{Method: "DELETE", Path: "/api/favorites/:id", Tag: "favorites", Summary: "Remove a favorite by ID",
+		Response: message},
+	{Method: "DELETE", Path: "/api/favorites/item", Tag: "favorites", Summary: "Remove a favorite by item",
+		Request: handlers.FavoriteItemRequest{}, Response: message},
+	{Method: "GET", Path: "/api/favorites/check/:type/:id", Tag: "favorites", Summary: "Check whether an item is a favorite",
+		Params:   []openapi.Param{{Name: "type", In: "path", Enum: []string{"file", "folder"}}},
+		Response: openapi.Fields{"is_favorite": false, "favorite_id": uint(0)}},
+	{Method: "GET", Path: "/api/recent-files", Tag: "recent", Summary: "Recently accessed items",
+		Response: openapi.Fields{"recent_files": []openapi.Fields{}}},
+	{Method: "POST", Path: "/api/recent-files/track/file/:id", Tag: "recent", Summary: "Record a file access",
+		Response: message},
+	{Method: "POST", Path: "/api/recent-files/track/folder/:id", Tag: "recent", Summary: "Record a folder access",
+		Response: message},
+
+	// Sharing
+	{Method: "POST", Path: "/api/files/:id/share", Tag: "sharing", Summary: "Share a file",
+		Request: handlers.CreateShareRequest{}, Response: openapi.Fields{"message": "", "share": models.FileShare{}, "share_url": ""}},
+	{Method: "GET", Path: "/api/files/:id/shares", Tag: "sharing", Summary: "Shares of a file",
+		Response: openapi.Fields{"shares": []models.FileShare{}}},
+	{Method: "POST", Path: "/api/folders/:id/share", Tag: "sharing", Summary: "Share a folder and everything inside it",
+		Request: handlers.CreateShareRequest{}, Response: shareResult},
+	{Method: "GET", Path: "/api/folders/:id/shares", Tag: "sharing", Summary: "Shares of a folder",
+		Response: openapi.Fields{"shares": []models.FileShare{}}},
+	{Method: "GET", Path: "/api/shares", Tag: "sharing", Summary: "All shares of the current user",
+		Response: openapi.Fields{"shares": []models.FileShare{}}},
+	{Method: "PUT", Path: "/api/shares/:share_id", Tag: "sharing", Summary: "Change the settings of a share",
+		Request: handlers.UpdateShareRequest{}, Response: shareResult},
+	{Method: "POST", Path: "/api/shares/:share_id/disable", Tag: "sharing", Summary: "Turn a share link off without deleting it",
+		Response: shareResult},
+	{Method: "POST", Path: "/api/shares/:share_id/enable", Tag: "sharing", Summary: "Turn a disabled share link back on",
+		Response: shareResult},
+	{Method: "DELETE", Path: "/api/shares/:share_id", Tag: "sharing", Summary: "Revoke a share",
+		Response: message},
+	{Method: "GET", Path: "/api/shares/analytics", Tag: "sharing", Summary: "Access analytics of all shares of the current user",
+		Params: []openapi.Param{analyticsDays}, Response: openapi.Fields{"analytics": handlers.ShareAnalytics{}, "shares": []handlers.ShareStats{}}},
+	{Method: "GET", Path: "/api/shares/analytics/export", Tag: "sharing", Summary: "Accesses of all shares of the current user as CSV",
+		Params: []openapi.Param{analyticsDays}, ContentType: "text/csv"},
+	{Method: "GET", Path: "/api/shares/:share_id/analytics", Tag: "sharing", Summary: "Access analytics of a share",
+		Params: []openapi.Param{analyticsDays}, Response: openapi.Fields{"share_id": uint(0), "analytics": handlers.ShareAnalytics{}}},
+	{Method: "GET", Path: "/api/shares/:share_id/analytics/export", Tag: "sharing", Summary: "Accesses of a share as CSV",
+		Params: []openapi.Param{analyticsDays}, ContentType: "text/csv"},
+	{Method: "GET", Path: "/share/:token", Tag: "sharing", Public: true, Summary: "Open a public share, or a password share with a session",
+		Params: []openapi.Param{shareSession, shareFolder}, Response: sharedFile, HTML: true},
+	{Method: "POST", Path: "/share/:token", Tag: "sharing", Public: true, Summary: "Unlock a password share from its landing page",
+		Form: []openapi.FormField{{Name: "password", Required: true}}, Status: 303},
+	{Method: "POST", Path: "/share/:token/access", Tag: "sharing", Public: true, Summary: "Open a share, with password when required",
+		Params: []openapi.Param{shareSession, shareFolder}, Request: handlers.ShareAccessRequest{}, RequestOptional: true, Response: unlockedShare},
+	{Method: "GET", Path: "/share/:token/download", Tag: "sharing", Public: true, Summary: "Download a shared file, or a shared folder as a zip",
+		Params: []openapi.Param{shareSession}, ContentType: binary},
+	{Method: "GET", Path: "/share/:token/preview", Tag: "sharing", Public: true, Summary: "View a shared file inline",
+		Params: []openapi.Param{shareSession, previewFormat}, ContentType: binary},
+	{Method: "GET", Path: "/share/:token/files/:file_id/download", Tag: "sharing", Public: true, Summary: "Download a file inside a shared folder",
+		Params: []openapi.Param{shareSession}, ContentType: binary},
+	{Method: "GET", Path: "/share/:token/files/:file_id/preview", Tag: "sharing", Public: true, Summary: "View a file inside a shared folder inline",
+		Params: []openapi.Param{shareSession, previewFormat}, ContentType: binary},
+
+	// Sync
+	{Method: "GET", Path: "/api/changes", Tag: "sync", Summary: "Change feed after a cursor",
+		Params: []openapi.Param{
+			{Name: "cursor", Type: "integer", Description: "last cursor seen, 0 for the beginning"},
+			{Name: "limit", Type: "integer", Description: "page size, at most 1000"},
+			driveID,
+		},
+		Response: openapi.Fields{"changes": []models.Change{}, "cursor": uint64(0), "has_more": false}},
+	{Method: "GET", Path: "/api/changes/latest", Tag: "sync", Summary: "Newest change cursor",
+		Params:   []openapi.Param{driveID},
+		Response: openapi.Fields{"cursor": uint64(0)}},
+
+	// Groups
+	{Method: "GET", Path: "/api/groups", Tag: "groups", Summary: "Groups of the current user",
+		Response: openapi.Fields{"groups": []models.Group{}}},
+	{Method: "POST", Path: "/api/groups", Tag: "groups", Summary: "Create a group",
+		Request: handlers.CreateGroupRequest{}, Response: groupResult, Status: 201},
+	{Method: "GET", Path: "/api/groups/:id", Tag: "groups", Summary: "Group with members and team drives",
+		Response: openapi.Fields{"group": models.Group{}, "drives": []models.TeamDrive{}}},
+	{Method: "PUT", Path: "/api/groups/:id", Tag: "groups", Summary: "Rename or describe a group (group admin)",
+		Request: handlers.UpdateGroupRequest{}, Response: groupResult},
+	{Method: "DELETE", Path: "/api/groups/:id", Tag: "groups", Summary: "Delete a group without team drives (group admin)",
+		Response: message},
+	{Method: "POST", Path: "/api/groups/:id/members", Tag: "groups", Summary: "Add a member (group admin)",
+		Request: handlers.AddGroupMemberRequest{}, Response: groupMember, Status: 201},
+	{Method: "PUT", Path: "/api/groups/:id/members/:user_id", Tag: "groups", Summary: "Grant or revoke group admin (group admin)",
+		Request: handlers.UpdateGroupMemberRequest{}, Response: groupMember},
+	{Method: "DELETE", Path: "/api/groups/:id/members/:user_id", Tag: "groups", Summary: "Remove a member or leave the group",
+		Response: message},
+
+	// Team drives
+	{Method: "GET", Path: "/api/drives", Tag: "drives", Summary: "Team drives of the current user",
+		Response: openapi.Fields{"drives": []models.TeamDrive{}}},
+	{Method: "POST", Path: "/api/drives", Tag: "drives", Summary: "Create a team drive (group admin)",
+		Request: handlers.CreateDriveRequest{}, Response: driveResult, Status: 201},
+	{Method: "GET", Path: "/api/drives/:id", Tag: "drives", Summary: "Team drive with member roles",
+		Response: openapi.Fields{"drive": models.TeamDrive{}, "members": []handlers.DriveMember{}}},
+	{Method: "PUT", Path: "/api/drives/:id", Tag: "drives", Summary: "Update name, quota or default role (manager)",
+		Request: handlers.UpdateDriveRequest{}, Response: driveResult},
+	{Method: "DELETE", Path: "/api/drives/:id", Tag: "drives", Summary: "Delete an empty team drive (manager)",
+		Response: message},
+	{Method: "PUT", Path: "/api/drives/:id/members/:user_id", Tag: "drives", Summary: "Set a member's drive role (manager)",
+		Request: handlers.SetDriveMemberRequest{}, Response: openapi.Fields{"member": models.TeamDriveMember{}}},
+	{Method: "DELETE", Path: "/api/drives/:id/members/:user_id", Tag: "drives", Summary: "Reset a member to the default role (manager)",
+		Response: message},
+
+	// Notifications
+	{Method: "GET", Path: "/api/notifications", Tag: "notifications", Summary: "Notifications of the current user, newest first",
+		Params:   []openapi.Param{{Name: "unread", Type: "boolean", Description: "only unread notifications"}},
+		Response: openapi.Fields{"notifications": []models.Notification{}, "unread": int64(0)}},
+	{Method: "POST", Path: "/api/notifications/read-all", Tag: "notifications", Summary: "Mark all notifications as read",
+		Response: openapi.Fields{"message": "", "updated": int64(0)}},
+	{Method: "POST", Path: "/api/notifications/:id/read", Tag: "notifications", Summary: "Mark a notification as read",
+		Response: openapi.Fields{"notification": models.Notification{}}},
+
+	// Analytics
+	{Method: "GET", Path: "/api/analytics/system", Tag: "analytics", Summary: "System-wide analytics (admin)",
+		Response: handlers.AnalyticsData{}},
+	{Method: "GET", Path: "/api/analytics/user", Tag: "analytics", Summary: "Analytics for the current user",
+		Response: handlers.UserAnalytics{}},
+
+	// Administration
+	{Method: "GET", Path: "/api/admin/users", Tag: "admin", Summary: "List users",
+		Response: openapi.Fields{"users": []models.User{}}},
+	{Method: "POST", Path: "/api/admin/users", Tag: "admin", Summary: "Create a user",
+		Request: handlers.RegisterRequest{}, Response: openapi.Fields{"user": models.User{}}, Status: 201},
+	{Method: "PUT", Path: "/api/admin/users/:id/role", Tag: "admin", Summary: "Assign a role to a user",
+		Request: handlers.UpdateUserRoleRequest{}, Response: openapi.Fields{"user": models.User{}}},
+	{Method: "POST", Path: "/api/admin/users/:id/disable", Tag: "admin", Summary: "Disable login for a user",
+		Response: openapi.Fields{"user": models.User{}}},
+	{Method: "POST", Path: "/api/admin/users/:id/enable", Tag: "admin", Summary: "Re-enable a disabled user",
+		Response: openapi.Fields{"user": models.User{}}},
+	{Method: "POST", Path: "/api/admin/users/:id/reset-password", Tag: "admin", Summary: "Set a new password for a user",
+		Request: handlers.ResetPasswordRequest{}, Response: message},
+	{Method: "DELETE", Path: "/api/admin/users/:id", Tag: "admin", Summary: "Delete a user, transferring or purging their files",
+		Params: []openapi.Param{
+			{Name: "transfer_to", Type: "integer", Description: "user who receives the files in a new top-level folder"},
+			{Name: "purge", Type: "boolean", Description: "delete the files instead"},
+		},
+		Response: openapi.Fields{"message": "", "folder": models.Folder{}}},
+	{Method: "GET", Path: "/api/admin/files", Tag: "admin", Summary: "Browse another user's files",
+		Params: []openapi.Param{
+			{Name: "user_id", Type: "integer", Required: true},
+			folderID,
+		},
+		Response: openapi.Fields{"user": models.User{}, "folders": []models.Folder{}, "files": []models.File{}}},
+	{Method: "GET", Path: "/api/admin/permissions", Tag: "admin", Summary: "Permissions that roles can grant",
+		Response: openapi.Fields{"permissions": []authz.Definition{}}},
+	{Method: "GET", Path: "/api/admin/roles", Tag: "admin", Summary: "List roles",
+		Response: openapi.Fields{"roles": []models.Role{}}},
+	{Method: "POST", Path: "/api/admin/roles", Tag: "admin", Summary: "Define a role",
+		Request: handlers.CreateRoleRequest{}, Response: roleResult, Status: 201},
+	{Method: "PUT", Path: "/api/admin/roles/:id", Tag: "admin", Summary: "Change a role's description or permissions",
+		Request: handlers.UpdateRoleRequest{}, Response: roleResult},
+	{Method: "DELETE", Path: "/api/admin/roles/:id", Tag: "admin", Summary: "Delete an unused custom role",
+		Response: message},
+	{Method: "GET", Path: "/api/admin/config", Tag: "admin", Summary: "Server configuration",
+		Response: openapi.Fields{"config": handlers.ConfigResponse{}, "message": ""}},
+	{Method: "GET", Path: "/api/admin/cors", Tag: "admin", Summary: "CORS configuration",
+		Response: openapi.Fields{"cors": openapi.Fields{"origins": []string{}, "methods": []string{}, "headers": []string{}}, "message": "", "note": ""}},
+	{Method: "GET", Path: "/api/admin/maintenance", Tag: "admin", Summary: "Maintenance mode",
+		Response: openapi.Fields{"maintenance": maintenance.State{}}},
+	{Method: "PUT", Path: "/api/admin/maintenance", Tag: "admin", Summary: "Turn maintenance mode on or off; changes are rejected with 503 while it is on",
+		Request: handlers.MaintenanceRequest{}, Response: openapi.Fields{"maintenance": maintenance.State{}, "message": ""}},
+}
+
+var (
+	groupResult = openapi.Fields{"group": models.Group{}}
+	groupMember = openapi.Fields{"member": models.GroupMember{}}
+	driveResult = openapi.Fields{"drive": models.TeamDrive{}}
+	roleResult  = openapi.Fields{"role": models.Role{}}
+	movedFile   = openapi.Fields{"file": models.File{}, "skipped": false}
+	movedFolder = openapi.Fields{"folder": models.Folder{}, "skipped": false}
+
+	versioningPolicy = openapi.Fields{"policy": models.VersioningPolicy{}}
+)
+
+// sharedFile has file for file shares. Folder shares have folder and the
+// contents of current_folder instead.
+var sharedFile = openapi.Fields{
+	"id":             uint(0),
+	"file":           models.File{},
+	"folder":         models.Folder{},
+	"current_folder": models.Folder{},
+	"folders":        []models.Folder{},
+	"files":          []models.File{},
+	"shared_by":      "",
+	"share_type":     "",
+	"allow_preview":  false,
+	"created_at":     time.Time{},
+}
+
+// unlockedShare adds the session that a correct password starts
+var unlockedShare = openapi.Fields{
+	"id":                 uint(0),
+	"file":               models.File{},
+	"folder":             models.Folder{},
+	"current_folder":     models.Folder{},
+	"folders":            []models.Folder{},
+	"files":              []models.File{},
+	"shared_by":          "",
+	"share_type":         "",
+	"allow_preview":      false,
+	"created_at":         time.Time{},
+	"session_token":      "",
+	"session_expires_at": time.Time{},
+}
+
+var shareResult = openapi.Fields{"message": "", "share": models.FileShare{}, "share_url": ""}
+
+var analyticsDays = openapi.Param{Name: "days", Type: "integer", Description: "number of days up to today, 1 to 365, default 30"}
+
+var shareSession = openapi.Param{Name: "session", Description: "session token of an unlocked password share, instead of the share_session_{share_id} cookie (path /share/)"}
+
+// shareFolder picks the folder of a folder share to list
+var shareFolder = openapi.Param{Name: "folder", Type: "integer", Description: "ID of a folder inside a shared folder to list; defaults to the shared folder"}
+
+// previewFormat chooses between rendered Markdown and code pages and the file
+// as it is
+var previewFormat = openapi.Param{Name: "format", Enum: []string{"rendered", "raw"}, Description: "rendered (default) shows Markdown and code as HTML pages, raw as plain text"}
@@ -9,8 +9,17 @@ func SetupSharingRoutes(router *gin.RouterGroup) {
 	// Protected sharing routes (require authentication)
 	router.POST("/files/:id/share", handlers.CreateFileShare)
 	router.GET("/files/:id/shares", handlers.GetFileShares)
+	router.POST("/folders/:id/share", handlers.CreateFolderShare)
+	router.GET("/folders/:id/shares", handlers.GetFolderShares)
 	router.GET("/shares", handlers.GetUserShares)
+	router.PUT("/shares/:share_id", handlers.UpdateFileShare)
 	router.DELETE("/shares/:share_id", handlers.DeleteFileShare)
+	router.POST("/shares/:share_id/disable", handlers.DisableFileShare)
+	router.POST("/shares/:share_id/enable", handlers.EnableFileShare)
+	router.GET("/shares/analytics", handlers.GetUserShareAnalytics)
+	router.GET("/shares/analytics/export", handlers.ExportUserShareAccesses)
+	router.GET("/shares/:share_id/analytics", handlers.GetShareAnalytics)
+	router.GET("/shares/:share_id/analytics/export", handlers.ExportShareAccesses)
 }
 
 func SetupPublicSharingRoutes(router *gin.Engine) {
@@ -19,6 +28,10 @@ func SetupPublicSharingRoutes(router *gin.Engine) {
 	{
 		shareGroup.POST("/:token/access", handlers.AccessSharedFile)
 		shareGroup.GET("/:token/download", handlers.DownloadSharedFile)
-		shareGroup.GET("/:token", handlers.AccessSharedFile) // For GET requests without password
+		shareGroup.GET("/:token/preview", handlers.PreviewSharedFile)
+		shareGroup.GET("/:token/files/:file_id/download", handlers.DownloadSharedFile) // Files inside folder shares
+		shareGroup.GET("/:token/files/:file_id/preview", handlers.PreviewSharedFile)
+		shareGroup.GET("/:token", handlers.OpenShare) // Landing page for browsers, JSON for API clients
+		shareGroup.POST("/:token", handlers.UnlockShare) // Password form of the landing page
 	}
 }
\ No newline at end of file
@@ -0,0 +1,74 @@
+// Package sharing decides whether share links can still be used and ends the
+// ones that expired or ran out of downloads.
+package sharing
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+)
+
+// StatusAt returns the status a share has at the given time by its expiry and
+// download limit. Disabling a share is separate from its status.
+func StatusAt(share *models.FileShare, now time.Time) string {
+	switch {
+	case share.ExpiresAt != nil && !now.Before(*share.ExpiresAt):
+		return models.ShareExpired
+	case share.MaxDownloads != nil && share.DownloadCount >= *share.MaxDownloads:
+		return models.ShareExhausted
+	}
+	return models.ShareActive
+}
+
+// ExpireAll marks the active shares that expired or reached their download
+// limit and notifies their creators
+func ExpireAll(db *gorm.DB) error {
+	now := time.Now()
+	var ended []models.FileShare
+	if err := db.Preload("File").Preload("Folder").
+		Where("status = ?", models.ShareActive).
+		Where("(expires_at IS NOT NULL AND expires_at <= ?) OR (max_downloads IS NOT NULL AND download_count >= max_downloads)", now).
+		Find(&ended).Error; err != nil {
+		return err
+	}
+
+	for i := range ended {
+		share := &ended[i]
+		status := StatusAt(share, now)
+		if status == models.ShareActive {
+			continue
+		}
+
+		// Skip shares whose status changed since they were read, e.g. by an
+		// update of their settings
+		result := db.Model(&models.FileShare{}).
+			Where("id = ? AND status = ?", share.ID, models.ShareActive).
+			Update("status", status)
+		if result.Error != nil {
+			return fmt.Errorf("share %d: %w", share.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		notification := models.Notification{
+			UserID:  share.SharedBy,
+			Type:    models.NotificationShareExpired,
+			Message: fmt.Sprintf("The share link of %s has expired", share.ItemName()),
+			FileID:  share.FileID,
+			ShareID: &share.ID,
+		}
+		if status == models.ShareExhausted {
+			notification.Type = models.NotificationShareExhausted
+			notification.Message = fmt.Sprintf("The share link of %s reached its download limit", share.ItemName())
+		}
+		if err := db.Create(&notification).Error; err != nil {
+			slog.Error("Failed to notify of the end of a share", "share_id", share.ID, "owner_id", share.SharedBy, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package textdiff computes line diffs and renders them as unified diffs or
+// side-by-side rows.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Line operations
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+	Change = "change" // side-by-side rows only
+)
+
+// maxEdits bounds the work of the Myers search. Inputs that differ by more
+// lines are reported as one replacement of the changed region.
+const maxEdits = 2000
+
+// Line is one line of a diff. OldLine and NewLine are 1-based and zero on the
+// side the line does not exist in.
+type Line struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Cell is one side of a side-by-side row
+type Cell struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Row is one line of a side-by-side rendering. Left is nil for inserted
+// lines and Right for deleted ones.
+type Row struct {
+	Op    string `json:"op"`
+	Left  *Cell  `json:"left"`
+	Right *Cell  `json:"right"`
+}
+
+// SplitLines splits text into lines without their line endings
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	return strings.Split(text, "\n")
+}
+
+// Lines returns the line diff that turns a into b
+func Lines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: Equal, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := suffix; i > 0; i-- {
+		x, y := len(a)-i, len(b)-i
+		lines = append(lines, Line{Op: Equal, OldLine: x + 1, NewLine: y + 1, Text: a[x]})
+	}
+	return lines
+}
+
+// Identical reports whether a diff has no changes
+func Identical(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return false
+		}
+	}
+	return true
+}
+
+// myers finds a shortest edit script with the greedy algorithm from Myers'
+// "An O(ND) Difference Algorithm". offA and offB number the lines.
+func myers(a, b []string, offA, offB int) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+
+	// v[k+limit+1] is the furthest x reached on diagonal k; trace[d] holds
+	// diagonals -d..d of v before step d
+	v := make([]int, 2*limit+3)
+	center := limit + 1
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[center-d:center+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[center+k-1] < v[center+k+1]) {
+				x = v[center+k+1]
+			} else {
+				x = v[center+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[center+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offA, offB)
+			}
+		}
+	}
+	return replace(a, b, offA, offB)
+}
+
+func backtrack(trace [][]int, a, b []string, offA, offB int) []Line {
+	x, y := len(a), len(b)
+	var reversed []Line
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		prevX, prevY := 0, 0
+		if d > 0 {
+			k := x - y
+			prevK := k - 1
+			if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+				prevK = k + 1
+			}
+			prevX = v[prevK+d]
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Line{Op: Equal, OldLine: offA + x + 1, NewLine: offB + y + 1, Text: a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, Line{Op: Insert, NewLine: offB + y + 1, Text: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, Line{Op: Delete, OldLine: offA + x + 1, Text: a[x]})
+		}
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, l := range reversed {
+		lines[len(reversed)-1-i] = l
+	}
+	return lines
+}
+
+// replace reports a as deleted and b as inserted
+func replace(a, b []string, offA, offB int) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, Line{Op: Delete, OldLine: offA + i + 1, Text: text})
+	}
+	for i, text := range b {
+		lines = append(lines, Line{Op: Insert, NewLine: offB + i + 1, Text: text})
+	}
+	return lines
+}
+
+// Unified renders a diff in unified format with the given number of context
+// lines around each change
+func Unified(lines []Line, oldName, newName string, context int) string {
+	if Identical(lines) {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	oldPos, newPos := 0, 0 // lines of each side before index i
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			oldPos++
+			newPos++
+			i++
+			continue
+		}
+
+		// The hunk starts context lines before the change and ends once more
+		// than 2*context equal lines follow the last change
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Op != Equal {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		stop := end + context
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+
+		oldStart, newStart := oldPos-(i-start), newPos-(i-start)
+		oldCount, newCount := 0, 0
+		for _, l := range lines[start:stop] {
+			if l.Op != Insert {
+				oldCount++
+			}
+			if l.Op != Delete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, l := range lines[start:stop] {
+			switch l.Op {
+			case Equal:
+				sb.WriteString(" ")
+			case Insert:
+				sb.WriteString("+")
+			case Delete:
+				sb.WriteString("-")
+			}
+			sb.WriteString(l.Text)
+			sb.WriteString("\n")
+		}
+
+		for _, l := range lines[i:stop] {
+			if l.Op != Insert {
+				oldPos++
+			}
+			if l.Op != Delete {
+				newPos++
+			}
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// hunkRange formats the start,count pair of a hunk header like GNU diff
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// SideBySide pairs the deleted and inserted lines of each change so that they
+// can be shown next to each other
+func SideBySide(lines []Line) []Row {
+	rows := make([]Row, 0, len(lines))
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			l := lines[i]
+			rows = append(rows, Row{Op: Equal, Left: &Cell{l.OldLine, l.Text}, Right: &Cell{l.NewLine, l.Text}})
+			i++
+			continue
+		}
+
+		var deleted, inserted []Line
+		for ; i < len(lines) && lines[i].Op != Equal; i++ {
+			if lines[i].Op == Delete {
+				deleted = append(deleted, lines[i])
+			} else {
+				inserted = append(inserted, lines[i])
+			}
+		}
+		for j := 0; j < len(deleted) || j < len(inserted); j++ {
+			row := Row{Op: Change}
+			if j < len(deleted) {
+				row.Left = &Cell{deleted[j].OldLine, deleted[j].Text}
+			} else {
+				row.Op = Insert
+			}
+			if j < len(inserted) {
+				row.Right = &Cell{inserted[j].NewLine, inserted[j].Text}
+			} else {
+				row.Op = Delete
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
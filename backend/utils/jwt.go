@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/sha256"
 	"errors"
 	"time"
 
@@ -46,4 +47,53 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	return nil, errors.New("invalid token")
+}
+
+// ShareSessionClaims grant access to one password-protected share. Key is
+// derived from the share's password hash, so changing the password ends
+// existing sessions.
+type ShareSessionClaims struct {
+	ShareID uint   `json:"share_id"`
+	Key     string `json:"key"`
+	jwt.RegisteredClaims
+}
+
+// shareSessionSecret keeps share sessions and login tokens from being
+// accepted in place of each other
+func shareSessionSecret() []byte {
+	sum := sha256.Sum256([]byte("share-session:" + config.Load().JWTSecret))
+	return sum[:]
+}
+
+func GenerateShareSessionToken(shareID uint, key string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	
+	claims := &ShareSessionClaims{
+		ShareID: shareID,
+		Key:     key,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(shareSessionSecret())
+	return signed, expiresAt, err
+}
+
+func ValidateShareSessionToken(tokenString string) (*ShareSessionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ShareSessionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return shareSessionSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	
+	if err != nil {
+		return nil, err
+	}
+	
+	if claims, ok := token.Claims.(*ShareSessionClaims); ok && token.Valid {
+		return claims, nil
+	}
+	
+	return nil, errors.New("invalid share session")
 }
\ No newline at end of file
@@ -0,0 +1,168 @@
+package versioning
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"a-drive-backend/chunkstore"
+	"a-drive-backend/models"
+)
+
+// chunkLock keeps CollectChunks from removing a chunk that a version being
+// stored is about to reference
+var chunkLock sync.RWMutex
+
+// Stats compares the size of old versions with the bytes stored for them
+type Stats struct {
+	Versions        int64 `json:"versions"`         // old versions, without the current ones
+	ChunkedVersions int64 `json:"chunked_versions"` // old versions kept in the chunk store
+	Chunks          int64 `json:"chunks"`           // distinct chunks those versions use
+	LogicalBytes    int64 `json:"logical_bytes"`    // total size of the old versions
+	PhysicalBytes   int64 `json:"physical_bytes"`   // bytes stored for them
+}
+
+func chunkStore() *chunkstore.Store {
+	return chunkstore.New(filepath.Join(os.Getenv("ROOT_DIRECTORY"), "chunks"))
+}
+
+// StoreChunked keeps src in the chunk store as the content of version, which
+// must already exist, and records it as chunked
+func StoreChunked(db *gorm.DB, version *models.FileVersion, src io.Reader) error {
+	chunkLock.RLock()
+	defer chunkLock.RUnlock()
+
+	store := chunkStore()
+	var hashes []string
+	err := chunkstore.Split(src, func(chunk []byte) error {
+		hash, err := store.Put(chunk)
+		if err != nil {
+			return err
+		}
+		// A chunk stored before is reused, which marks it as recently used
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "hash"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+		}).Create(&models.Chunk{Hash: hash, Size: int64(len(chunk))}).Error; err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("version_id = ?", version.ID).Delete(&models.VersionChunk{}).Error; err != nil {
+			return err
+		}
+		rows := make([]models.VersionChunk, len(hashes))
+		for i, hash := range hashes {
+			rows[i] = models.VersionChunk{VersionID: version.ID, Seq: i, ChunkHash: hash}
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		version.Chunked = true
+		version.FilePath = ""
+		return tx.Model(version).Select("chunked", "file_path").Updates(version).Error
+	})
+}
+
+// Open returns the content of a version, reassembling chunked versions
+func Open(db *gorm.DB, version *models.FileVersion) (io.ReadCloser, error) {
+	if !version.Chunked {
+		return os.Open(version.FilePath)
+	}
+
+	var hashes []string
+	if err := db.Model(&models.VersionChunk{}).Where("version_id = ?", version.ID).
+		Order("seq").
+		Pluck("chunk_hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+	return chunkStore().Open(hashes), nil
+}
+
+// CollectChunks removes chunks no version of an existing file uses anymore
+func CollectChunks(db *gorm.DB) error {
+	chunkLock.Lock()
+	defer chunkLock.Unlock()
+
+	live := db.Model(&models.FileVersion{}).Select("file_versions.id").
+		Joins("JOIN files ON files.id = file_versions.file_id AND files.deleted_at IS NULL")
+	if err := db.Where("version_id NOT IN (?)", live).Delete(&models.VersionChunk{}).Error; err != nil {
+		return err
+	}
+
+	var hashes []string
+	if err := db.Model(&models.Chunk{}).
+		Where("hash NOT IN (?)", db.Model(&models.VersionChunk{}).Select("chunk_hash")).
+		Pluck("hash", &hashes).Error; err != nil {
+		return err
+	}
+
+	store := chunkStore()
+	for _, hash := range hashes {
+		if err := store.Remove(hash); err != nil {
+			return err
+		}
+		if err := db.Where("hash = ?", hash).Delete(&models.Chunk{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StatsFor sums up the old versions of the files selected by fileIDs, a
+// subquery of file IDs
+func StatsFor(db *gorm.DB, fileIDs *gorm.DB) (Stats, error) {
+	oldVersions := func() *gorm.DB {
+		return db.Model(&models.FileVersion{}).
+			Joins("JOIN files ON files.id = file_versions.file_id AND files.deleted_at IS NULL").
+			Where("file_versions.file_id IN (?) AND file_versions.version <> files.current_version", fileIDs)
+	}
+
+	var versions struct {
+		Count      int64
+		Chunked    int64
+		Logical    int64
+		WholeBytes int64
+	}
+	if err := oldVersions().Select(
+		"COUNT(*) AS count",
+		"COALESCE(SUM(CASE WHEN file_versions.chunked THEN 1 ELSE 0 END), 0) AS chunked",
+		"COALESCE(SUM(file_versions.size), 0) AS logical",
+		"COALESCE(SUM(CASE WHEN file_versions.chunked THEN 0 ELSE file_versions.size END), 0) AS whole_bytes",
+	).Scan(&versions).Error; err != nil {
+		return Stats{}, err
+	}
+
+	var chunks struct {
+		Count int64
+		Bytes int64
+	}
+	used := db.Model(&models.VersionChunk{}).Select("chunk_hash").
+		Where("version_id IN (?)", oldVersions().Select("file_versions.id"))
+	if err := db.Model(&models.Chunk{}).Where("hash IN (?)", used).
+		Select("COUNT(*) AS count", "COALESCE(SUM(size), 0) AS bytes").
+		Scan(&chunks).Error; err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Versions:        versions.Count,
+		ChunkedVersions: versions.Chunked,
+		Chunks:          chunks.Count,
+		LogicalBytes:    versions.Logical,
+		PhysicalBytes:   versions.WholeBytes + chunks.Bytes,
+	}, nil
+}
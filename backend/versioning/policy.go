@@ -0,0 +1,38 @@
+// Package versioning resolves the versioning policy of a file, prunes old
+// versions according to its retention rules and keeps the content of large
+// old versions in a chunk store where versions share unchanged chunks.
+package versioning
+
+import (
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+)
+
+// PolicyFor returns the policy that applies to a file: the one of its nearest
+// folder that has a policy, or else the owner's default for personal files.
+// It returns nil when no policy applies.
+func PolicyFor(db *gorm.DB, file *models.File) *models.VersioningPolicy {
+	folderID := file.FolderID
+	for folderID != nil {
+		var policy models.VersioningPolicy
+		if err := db.Where("folder_id = ?", *folderID).First(&policy).Error; err == nil {
+			return &policy
+		}
+
+		var folder models.Folder
+		if err := db.Select("id", "parent_id").First(&folder, *folderID).Error; err != nil {
+			break
+		}
+		folderID = folder.ParentID
+	}
+
+	if file.DriveID != nil {
+		return nil
+	}
+	var policy models.VersioningPolicy
+	if err := db.Where("user_id = ?", file.UserID).First(&policy).Error; err != nil {
+		return nil
+	}
+	return &policy
+}
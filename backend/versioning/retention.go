@@ -0,0 +1,120 @@
+package versioning
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"a-drive-backend/models"
+)
+
+// Expired returns the old versions the policy no longer keeps. versions must
+// be the old versions of one file, newest first. A version is kept when any
+// of the keep rules selects it, or when there are no keep rules at all;
+// MaxTotalBytes then drops the oldest of the kept versions until they fit.
+func Expired(policy *models.VersioningPolicy, versions []models.FileVersion, now time.Time) []models.FileVersion {
+	keepAll := policy.KeepLast == 0 && policy.KeepDailyDays == 0 && policy.KeepWeeklyWeeks == 0
+	dailySince := now.AddDate(0, 0, -policy.KeepDailyDays)
+	weeklySince := now.AddDate(0, 0, -7*policy.KeepWeeklyWeeks)
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+
+	var expired []models.FileVersion
+	var total int64
+	for i, v := range versions {
+		keep := keepAll || i < policy.KeepLast
+
+		if policy.KeepDailyDays > 0 && v.CreatedAt.After(dailySince) {
+			day := v.CreatedAt.Format("2006-01-02")
+			if !days[day] {
+				days[day] = true
+				keep = true
+			}
+		}
+		if policy.KeepWeeklyWeeks > 0 && v.CreatedAt.After(weeklySince) {
+			year, week := v.CreatedAt.ISOWeek()
+			key := fmt.Sprintf("%d-%d", year, week)
+			if !weeks[key] {
+				weeks[key] = true
+				keep = true
+			}
+		}
+
+		if keep && policy.MaxTotalBytes > 0 {
+			total += v.Size
+			keep = total <= policy.MaxTotalBytes
+		}
+		if !keep {
+			expired = append(expired, v)
+		}
+	}
+	return expired
+}
+
+// Prune deletes the old versions of a file that the policy no longer keeps,
+// together with their content. Pinned versions are left alone and do not
+// count towards the rules. It returns how many versions were deleted.
+func Prune(db *gorm.DB, file *models.File, policy *models.VersioningPolicy, now time.Time) (int, error) {
+	if !file.VersioningEnabled || !policy.HasRetention() {
+		return 0, nil
+	}
+
+	var versions []models.FileVersion
+	if err := db.Where("file_id = ? AND version != ? AND pinned = ?", file.ID, file.CurrentVersion, false).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return 0, err
+	}
+
+	expired := Expired(policy, versions, now)
+	for i := range expired {
+		if err := Delete(db, file, &expired[i]); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+// PruneAll applies the retention rules to every versioned file
+func PruneAll(db *gorm.DB) error {
+	var files []models.File
+	if err := db.Where("versioning_enabled = ?", true).Find(&files).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range files {
+		policy := PolicyFor(db, &files[i])
+		if policy == nil {
+			continue
+		}
+		if _, err := Prune(db, &files[i], policy, now); err != nil {
+			return fmt.Errorf("file %d: %w", files[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Delete removes an old version of a file and its content
+func Delete(db *gorm.DB, file *models.File, version *models.FileVersion) error {
+	if err := db.Delete(version).Error; err != nil {
+		return err
+	}
+	removeUnreferenced(db, file, version.FilePath)
+	return nil
+}
+
+// removeUnreferenced deletes a version blob unless it is the file's current
+// content or another version still points to it
+func removeUnreferenced(db *gorm.DB, file *models.File, path string) {
+	if path == "" || path == file.FilePath {
+		return
+	}
+	var count int64
+	db.Model(&models.FileVersion{}).Where("file_path = ?", path).Count(&count)
+	if count == 0 {
+		os.Remove(path)
+	}
+}